@@ -1,13 +1,17 @@
 package problems
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
 type Builder struct {
-	url string
-	f   []Option
+	url       string
+	mode      Mode
+	reference *bool
+	logger    Logger
+	f         []Option
 }
 
 type Option func(p DefaultParams) Problem
@@ -62,6 +66,42 @@ func (b *Builder) Type(format string, args ...interface{}) *Builder {
 	b.url = fmt.Sprintf(format, args...)
 	return b
 }
+
+// Mode sets the mode of the builder, overriding the global mode set by SetMode.
+func (b *Builder) Mode(m Mode) *Builder {
+	b.mode = m
+	return b
+}
+
+// Reference enables or disables reference generation of the builder, overriding SetReference.
+func (b *Builder) Reference(enabled bool) *Builder {
+	b.reference = &enabled
+	return b
+}
+
+// Logger sets the logger of the builder and of the problems it builds, overriding SetLogger.
+func (b *Builder) Logger(l Logger) *Builder {
+	b.logger = l
+	return b
+}
+
+// internal disables redaction and reference generation for problems built by Of, which applies them itself.
+func (b *Builder) internal() *Builder {
+	return b.Mode(Development).Reference(false)
+}
+func (b *Builder) references() bool {
+	if b.reference != nil {
+		return *b.reference
+	}
+	return referenceEnabled.Load()
+}
+func (b *Builder) redacts() bool {
+	m := b.mode
+	if m == 0 {
+		m = currentMode()
+	}
+	return m == Production
+}
 func (b *Builder) build(status int, detail string, opt ...Option) (sp Problem) {
 	if len(opt) > 0 {
 		var dp DefaultParams
@@ -76,6 +116,24 @@ func (b *Builder) build(status int, detail string, opt ...Option) (sp Problem) {
 	if dp, ok := sp.(DefaultParams); ok {
 		dp.SetParams(b.url, detail)
 	}
+	if dp := defaultProblemOf(sp); dp != nil && b.logger != nil {
+		dp.logger = b.logger
+	}
+	if b.references() {
+		setReference(sp, newReference())
+	}
+	if b.redacts() {
+		if dp := defaultProblemOf(sp); dp != nil {
+			detail, err := dp.Detail, dp.err
+			if ref, redacted := redact(sp); redacted {
+				loggerOf(sp).Log(context.Background(), Entry{
+					Level: LevelError, Message: detail, Err: err, Stack: true,
+					Fields: map[string]interface{}{ReferenceKey: ref},
+				})
+			}
+		}
+	}
+	localize(sp)
 	return sp
 }
 func (b *Builder) BadRequest(format string, args ...interface{}) Problem {
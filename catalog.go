@@ -0,0 +1,206 @@
+package problems
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"text/template"
+)
+
+// Definition describes a coded problem of a Catalog.
+type Definition struct {
+	Code   string `json:"code"`
+	Type   string `json:"type,omitempty"`
+	Status int    `json:"status"`
+	Title  string `json:"title,omitempty"`
+	// Detail is a text/template executed with the parameters given to Catalog.New.
+	Detail string `json:"detail,omitempty"`
+	tmpl   *template.Template
+}
+
+// Catalog holds problem definitions by code.
+type Catalog struct {
+	mu    sync.RWMutex
+	defs  map[string]Definition
+	types map[string]string
+}
+
+// NewCatalog returns a catalog of the definitions, validating them.
+func NewCatalog(defs ...Definition) (*Catalog, error) {
+	c := &Catalog{defs: make(map[string]Definition), types: make(map[string]string)}
+	if err := c.Add(defs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadCatalog reads a JSON array of definitions.
+func LoadCatalog(r io.Reader) (*Catalog, error) {
+	var defs []Definition
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&defs); err != nil {
+		return nil, fmt.Errorf("problems: invalid catalog: %w", err)
+	}
+	return NewCatalog(defs...)
+}
+
+// LoadCatalogFile reads a JSON array of definitions from the file.
+func LoadCatalogFile(name string) (*Catalog, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadCatalog(f)
+}
+
+// Add adds definitions. Codes and type URIs other than about:blank must be unique.
+// Nothing is added if a definition is invalid.
+func (c *Catalog) Add(defs ...Definition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	codes := make(map[string]bool, len(defs))
+	types := make(map[string]string, len(defs))
+	parsed := make([]Definition, 0, len(defs))
+	var errs []error
+	for _, def := range defs {
+		if err := def.parse(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := c.defs[def.Code]; ok || codes[def.Code] {
+			errs = append(errs, fmt.Errorf("problems: code '%s' is already defined", def.Code))
+			continue
+		}
+		codes[def.Code] = true
+		if def.Type != "" && def.Type != DefaultType {
+			code, ok := c.types[def.Type]
+			if !ok {
+				code, ok = types[def.Type]
+			}
+			if ok {
+				errs = append(errs, fmt.Errorf("problems: type '%s' of code '%s' is already defined by '%s'", def.Type, def.Code, code))
+				continue
+			}
+			types[def.Type] = def.Code
+		}
+		parsed = append(parsed, def)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, def := range parsed {
+		c.defs[def.Code] = def
+	}
+	for uri, code := range types {
+		c.types[uri] = code
+	}
+	return nil
+}
+
+func (def *Definition) parse() error {
+	if def.Code == "" {
+		return errors.New("problems: code is empty")
+	}
+	if def.Status < http.StatusBadRequest || def.Status > 599 {
+		return fmt.Errorf("problems: invalid status %d of code '%s'", def.Status, def.Code)
+	}
+	if def.Detail != "" {
+		tmpl, err := template.New(def.Code).Option("missingkey=error").Parse(def.Detail)
+		if err != nil {
+			return fmt.Errorf("problems: invalid detail of code '%s': %w", def.Code, err)
+		}
+		def.tmpl = tmpl
+	}
+	return nil
+}
+
+// Definition returns the definition of the code.
+func (c *Catalog) Definition(code string) (Definition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.defs[code]
+	return def, ok
+}
+
+// Definitions returns the definitions sorted by code.
+func (c *Catalog) Definitions() []Definition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	defs := make([]Definition, 0, len(c.defs))
+	for _, def := range c.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Code < defs[j].Code
+	})
+	return defs
+}
+
+// New builds the problem of the code, executing the detail template with params.
+// The detail is empty if the template fails, for example on a missing parameter, and the error is logged.
+// Options are applied after the Code, Type and Title of the definition.
+// An unknown code results in an Internal Server Error.
+func (c *Catalog) New(code string, params interface{}, opts ...Option) Problem {
+	def, ok := c.Definition(code)
+	if !ok {
+		return New(opts...).InternalServerError("undefined problem code '%s'", code)
+	}
+	detail, err := def.detail(params)
+	b := New(append(def.options(), opts...)...)
+	p := b.build(def.Status, detail, b.f...)
+	if err != nil {
+		loggerOf(p).Log(context.Background(), Entry{
+			Level: LevelWarn, Message: fmt.Sprintf("problems: detail of code '%s' failed", code), Err: err,
+		})
+	}
+	return p
+}
+
+func (def Definition) options() []Option {
+	opts := []Option{Code(def.Code)}
+	if def.Type != "" {
+		opts = append(opts, Type("%s", def.Type))
+	}
+	if def.Title != "" {
+		opts = append(opts, Title(def.Title))
+	}
+	return opts
+}
+
+func (def Definition) detail(params interface{}) (string, error) {
+	if def.tmpl == nil {
+		return "", nil
+	}
+	buf := &bytes.Buffer{}
+	if err := def.tmpl.Execute(buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Register registers the type URIs of the definitions with their status and title.
+func (c *Catalog) Register(r *Registry) error {
+	var errs []error
+	for _, def := range c.Definitions() {
+		if def.Type == "" || def.Type == DefaultType {
+			continue
+		}
+		title := def.Title
+		if title == "" {
+			title = http.StatusText(def.Status)
+		}
+		if err := r.Register(def.Type, func() Problem { return &DefaultProblem{} }, DefaultStatus(def.Status), DefaultTitle(title)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,121 @@
+package problems
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const catalogJSON = `[
+	{"code": "USER_NOT_FOUND", "type": "https://example.com/probs/user-not-found", "status": 404, "title": "User not found", "detail": "user {{.id}} does not exist"},
+	{"code": "QUOTA_EXCEEDED", "status": 429, "detail": "{{.used}} of {{.limit}} requests used"}
+]`
+
+func TestCatalog(t *testing.T) {
+	c, err := LoadCatalog(strings.NewReader(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := c.New("USER_NOT_FOUND", map[string]interface{}{"id": 42}, Instance("/users/42"))
+	dp := defaultProblemOf(p)
+	if dp.Status != http.StatusNotFound || dp.Code != "USER_NOT_FOUND" || dp.Type != "https://example.com/probs/user-not-found" ||
+		dp.Title != "User not found" || dp.Detail != "user 42 does not exist" || dp.Instance != "/users/42" {
+		t.Errorf("expect = %v, actual = %v", "USER_NOT_FOUND", p)
+	}
+
+	p = c.New("QUOTA_EXCEEDED", struct{ Used, Limit int }{Used: 10, Limit: 10})
+	dp = defaultProblemOf(p)
+	if dp.Status != http.StatusTooManyRequests || dp.Type != DefaultType || dp.Title != "Too Many Requests" || dp.Detail != "" {
+		t.Errorf("expect = %v, actual = %v", "QUOTA_EXCEEDED", p)
+	}
+	p = c.New("QUOTA_EXCEEDED", map[string]int{"used": 10, "limit": 10})
+	if dp = defaultProblemOf(p); dp.Detail != "10 of 10 requests used" {
+		t.Errorf("expect = %v, actual = %v", "10 of 10 requests used", dp.Detail)
+	}
+
+	p = c.New("UNKNOWN", nil)
+	if p.ProblemStatus() != http.StatusInternalServerError {
+		t.Errorf("expect = %v, actual = %v", http.StatusInternalServerError, p.ProblemStatus())
+	}
+	if defs := c.Definitions(); len(defs) != 2 || defs[0].Code != "QUOTA_EXCEEDED" {
+		t.Errorf("expect = %v, actual = %v", 2, defs)
+	}
+}
+
+func TestCatalog_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []Definition
+	}{
+		{name: "empty code", defs: []Definition{{Status: 400}}},
+		{name: "status", defs: []Definition{{Code: "A", Status: 200}}},
+		{name: "template", defs: []Definition{{Code: "A", Status: 400, Detail: "{{.id"}}},
+		{name: "code", defs: []Definition{{Code: "A", Status: 400}, {Code: "A", Status: 404}}},
+		{name: "type", defs: []Definition{{Code: "A", Type: "urn:a", Status: 400}, {Code: "B", Type: "urn:a", Status: 404}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewCatalog(tt.defs...); err == nil {
+			t.Errorf("%s: expect = error, actual = %v", tt.name, err)
+		}
+	}
+	c, err := NewCatalog(Definition{Code: "A", Status: 400}, Definition{Code: "B", Status: 400, Type: DefaultType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Add(Definition{Code: "C", Status: 400}, Definition{Code: "A", Status: 400}); err == nil {
+		t.Errorf("expect = error, actual = %v", err)
+	}
+	if _, ok := c.Definition("C"); ok {
+		t.Errorf("expect = %v, actual = %v", false, ok)
+	}
+	if _, err = LoadCatalog(strings.NewReader(`[{"code":"A","status":400,"unknown":1}]`)); err == nil {
+		t.Errorf("expect = error, actual = %v", err)
+	}
+}
+
+func TestCatalog_Register(t *testing.T) {
+	c, err := LoadCatalog(strings.NewReader(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	if err = c.Register(r); err != nil {
+		t.Fatal(err)
+	}
+	pt, ok := r.Lookup("https://example.com/probs/user-not-found")
+	if !ok || pt.Status != http.StatusNotFound || pt.Title != "User not found" {
+		t.Errorf("expect = %v, actual = %v", "user-not-found", pt)
+	}
+	if err = c.Register(r); err == nil {
+		t.Errorf("expect = error, actual = %v", err)
+	}
+
+	body := c.New("USER_NOT_FOUND", map[string]int{"id": 1}).String()
+	p, err := Bind(context.TODO(), http.StatusNotFound, []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dp := defaultProblemOf(p); dp.Code != "USER_NOT_FOUND" {
+		t.Errorf("expect = %v, actual = %v", "USER_NOT_FOUND", dp.Code)
+	}
+}
+
+func TestCatalog_MissingParam(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+
+	c, err := LoadCatalog(strings.NewReader(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := c.New("USER_NOT_FOUND", map[string]interface{}{"name": "alice"})
+	if dp := defaultProblemOf(p); dp.Detail != "" || dp.Status != http.StatusNotFound {
+		t.Errorf("expect = %v, actual = %v", "", dp.Detail)
+	}
+	if len(l.entries) != 1 || l.entries[0].Level != LevelWarn || l.entries[0].Err == nil ||
+		!strings.Contains(l.entries[0].Err.Error(), "id") {
+		t.Errorf("expect = %v, actual = %v", "missing id", l.entries)
+	}
+}
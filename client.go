@@ -0,0 +1,94 @@
+package problems
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+// DefaultMaxBodySize is the maximum size of a problem body read by Transport and CheckResponse.
+const DefaultMaxBodySize int64 = 1 << 20
+
+// Transport is an http.RoundTripper returning a ProblemError for problem responses.
+type Transport struct {
+	// Base is the underlying transport; http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+	// MaxBodySize limits the problem body; DefaultMaxBodySize is used if zero or less.
+	MaxBodySize int64
+	// Normalizer, if set, also converts error responses that are not problems, or whose problem body
+	// cannot be read, into ProblemError.
+	Normalizer *Normalizer
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResponse(req.Context(), res, t.MaxBodySize); err != nil {
+		pe := &ProblemError{}
+		if t.Normalizer == nil || errors.As(err, &pe) {
+			return nil, err
+		}
+	}
+	if t.Normalizer != nil && res.StatusCode >= http.StatusBadRequest {
+		if p := t.Normalizer.response(res, t.MaxBodySize); p != nil {
+			readHeaders(p, res.Header)
+			return nil, &ProblemError{problem: p, Response: res}
+		}
+	}
+	return res, nil
+}
+
+// CheckResponse returns a ProblemError if the response is an error with a problem body.
+// The body is replaced so that it can still be read through ProblemError.Response.
+func CheckResponse(res *http.Response) error {
+	ctx := context.Background()
+	if res.Request != nil {
+		ctx = res.Request.Context()
+	}
+	return checkResponse(ctx, res, DefaultMaxBodySize)
+}
+
+func checkResponse(ctx context.Context, res *http.Response, maxBodySize int64) error {
+	if res == nil || res.StatusCode < http.StatusBadRequest || !isProblemResponse(res) {
+		return nil
+	}
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize+1))
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	if int64(len(body)) > maxBodySize {
+		return fmt.Errorf("problem body exceeds %d bytes", maxBodySize)
+	}
+	p, err := Decode(ctx, res.StatusCode, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	readHeaders(p, res.Header)
+	return &ProblemError{problem: p, Response: res}
+}
+
+func isProblemResponse(res *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get(headers.ContentType))
+	if err != nil {
+		return false
+	}
+	return mediaType == mimetypes.ProblemJson || mediaType == mimetypes.ProblemXml
+}
@@ -0,0 +1,86 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			New(Instance("/users/1"), Code("NOT_FOUND")).NotFound("user not found").JSON(r.Context(), w)
+		case "/xml":
+			New(Instance("/users/1")).Conflict("conflict").XML(r.Context(), w)
+		case "/plain":
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{}}
+
+	_, err := client.Get(srv.URL + "/json")
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) {
+		t.Fatalf("expect = ProblemError, actual = %v", err)
+	}
+	dp := defaultProblemOf(pe.Problem())
+	if dp.Status != http.StatusNotFound || dp.Code != "NOT_FOUND" || dp.Instance != "/users/1" {
+		t.Errorf("expect = 404 NOT_FOUND /users/1, actual = %d %s %s", dp.Status, dp.Code, dp.Instance)
+	}
+	if pe.Response == nil || pe.Response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect = %v, actual = %v", http.StatusNotFound, pe.Response)
+	}
+	if body, _ := io.ReadAll(pe.Response.Body); !strings.Contains(string(body), "user not found") {
+		t.Errorf("expect = %v, actual = %s", "user not found", body)
+	}
+
+	_, err = client.Get(srv.URL + "/xml")
+	if !errors.As(err, &pe) || pe.Problem().ProblemStatus() != http.StatusConflict {
+		t.Errorf("expect = %v, actual = %v", http.StatusConflict, err)
+	}
+
+	res, err := client.Get(srv.URL + "/plain")
+	if err != nil || res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expect = %v, actual = %v", http.StatusInternalServerError, err)
+	}
+	res, err = client.Get(srv.URL + "/")
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("expect = %v, actual = %v", http.StatusOK, err)
+	}
+}
+
+func TestTransport_MaxBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		New().BadRequest(strings.Repeat("x", 100)).JSON(r.Context(), w)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{MaxBodySize: 64}}
+	_, err := client.Get(srv.URL)
+	pe := &ProblemError{}
+	if err == nil || errors.As(err, &pe) {
+		t.Errorf("expect = size error, actual = %v", err)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	New().TooManyRequests("slow down").JSON(context.TODO(), w)
+	err := CheckResponse(w.Result())
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) || pe.Problem().ProblemStatus() != http.StatusTooManyRequests {
+		t.Errorf("expect = %v, actual = %v", http.StatusTooManyRequests, err)
+	}
+	w = httptest.NewRecorder()
+	w.WriteHeader(http.StatusNoContent)
+	if err = CheckResponse(w.Result()); err != nil {
+		t.Errorf("expect = nil, actual = %v", err)
+	}
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+
+	"github.com/goccha/problems"
+)
+
+type field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// definition is a catalog definition with the Go types of its parameters and custom fields.
+type definition struct {
+	problems.Definition
+	// Params maps detail template parameters to Go types; string by default.
+	Params map[string]string `json:"params,omitempty"`
+	// Fields are extension members held by a generated problem type.
+	Fields []field `json:"fields,omitempty"`
+}
+
+func parseJSON(src []byte) ([]definition, error) {
+	var defs []definition
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&defs); err != nil {
+		return nil, fmt.Errorf("invalid catalog: %w", err)
+	}
+	return defs, nil
+}
+
+// parseGo collects the Definition composite literals of a Go file.
+// Values are literals, constants declared in the file, net/http status constants or concatenations of them.
+// Parameters and fields are declared by //problemgen:param and //problemgen:field directives
+// in the comment preceding a literal.
+func parseGo(name string, src []byte) ([]definition, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	r := newResolver(f)
+	comments := map[int]*ast.CommentGroup{}
+	for _, c := range f.Comments {
+		comments[fset.Position(c.End()).Line] = c
+	}
+	var defs []definition
+	var errs []error
+	add := func(lit *ast.CompositeLit) {
+		def, err := r.definition(lit)
+		if err == nil {
+			err = directives(&def, comments[fset.Position(lit.Pos()).Line-1])
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fset.Position(lit.Pos()), err))
+		} else {
+			defs = append(defs, def)
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if isDefinitionType(lit.Type) {
+			add(lit)
+			return false
+		}
+		// elements of []Definition may elide their type
+		if t, ok := lit.Type.(*ast.ArrayType); ok && isDefinitionType(t.Elt) {
+			for _, elt := range lit.Elts {
+				if el, ok := elt.(*ast.CompositeLit); ok && el.Type == nil {
+					add(el)
+				}
+			}
+		}
+		return true
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return defs, nil
+}
+
+func isDefinitionType(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == "Definition"
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "Definition"
+	}
+	return false
+}
+
+// directives reads the parameter and field types declared in the comment of a definition:
+//
+//	//problemgen:param balance int
+//	//problemgen:field accounts []string
+func directives(def *definition, c *ast.CommentGroup) error {
+	if c == nil {
+		return nil
+	}
+	for _, line := range c.List {
+		directive, args, ok := strings.Cut(strings.TrimPrefix(line.Text, "//"), " ")
+		if !strings.HasPrefix(directive, "problemgen:") {
+			continue
+		}
+		name, typ, _ := strings.Cut(strings.TrimSpace(args), " ")
+		typ = strings.TrimSpace(typ)
+		if !ok || name == "" || typ == "" {
+			return fmt.Errorf("%s must have a name and a type", directive)
+		}
+		switch directive {
+		case "problemgen:param":
+			if def.Params == nil {
+				def.Params = map[string]string{}
+			}
+			def.Params[name] = typ
+		case "problemgen:field":
+			def.Fields = append(def.Fields, field{Name: name, Type: typ})
+		default:
+			return fmt.Errorf("unknown directive %s", directive)
+		}
+	}
+	return nil
+}
+
+// resolver evaluates the constant expressions of a Go catalog.
+type resolver struct {
+	consts map[string]ast.Expr
+	// http is the name under which net/http is imported
+	http      string
+	resolving map[string]bool
+}
+
+func newResolver(f *ast.File) *resolver {
+	r := &resolver{consts: map[string]ast.Expr{}, resolving: map[string]bool{}}
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == "net/http" {
+			r.http = "http"
+			if imp.Name != nil {
+				r.http = imp.Name.Name
+			}
+		}
+	}
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+			for _, spec := range gen.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok && len(vs.Names) == len(vs.Values) {
+					for i, name := range vs.Names {
+						r.consts[name.Name] = vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return r
+}
+
+func (r *resolver) definition(lit *ast.CompositeLit) (definition, error) {
+	var def definition
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return def, fmt.Errorf("definition must use keyed fields")
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return def, fmt.Errorf("invalid definition key")
+		}
+		v, err := r.value(kv.Value)
+		if err != nil {
+			return def, fmt.Errorf("value of %s: %w", key.Name, err)
+		}
+		switch key.Name {
+		case "Status":
+			status, ok := constant.Int64Val(v)
+			if v.Kind() != constant.Int || !ok {
+				return def, fmt.Errorf("invalid status %s", v)
+			}
+			def.Status = int(status)
+		case "Code", "Type", "Title", "Detail":
+			if v.Kind() != constant.String {
+				return def, fmt.Errorf("invalid %s %s", key.Name, v)
+			}
+			s := constant.StringVal(v)
+			switch key.Name {
+			case "Code":
+				def.Code = s
+			case "Type":
+				def.Type = s
+			case "Title":
+				def.Title = s
+			case "Detail":
+				def.Detail = s
+			}
+		default:
+			return def, fmt.Errorf("unknown definition field %s", key.Name)
+		}
+	}
+	return def, nil
+}
+
+// value evaluates a literal, a constant of the file, a net/http status constant or a concatenation of them.
+func (r *resolver) value(expr ast.Expr) (constant.Value, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if v := constant.MakeFromLiteral(e.Value, e.Kind, 0); v.Kind() != constant.Unknown {
+			return v, nil
+		}
+	case *ast.ParenExpr:
+		return r.value(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			break
+		}
+		x, err := r.value(e.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := r.value(e.Y)
+		if err != nil {
+			return nil, err
+		}
+		if x.Kind() == y.Kind() {
+			return constant.BinaryOp(x, token.ADD, y), nil
+		}
+	case *ast.Ident:
+		if c, ok := r.consts[e.Name]; ok && !r.resolving[e.Name] {
+			r.resolving[e.Name] = true
+			defer delete(r.resolving, e.Name)
+			return r.value(c)
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && r.http != "" && pkg.Name == r.http {
+			if status, ok := httpStatuses[e.Sel.Name]; ok {
+				return constant.MakeInt64(int64(status)), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s is not a constant of the catalog", types.ExprString(expr))
+}
+
+// httpStatuses are the net/http constants of the error statuses.
+var httpStatuses = map[string]int{
+	"StatusBadRequest":                    http.StatusBadRequest,
+	"StatusUnauthorized":                  http.StatusUnauthorized,
+	"StatusPaymentRequired":               http.StatusPaymentRequired,
+	"StatusForbidden":                     http.StatusForbidden,
+	"StatusNotFound":                      http.StatusNotFound,
+	"StatusMethodNotAllowed":              http.StatusMethodNotAllowed,
+	"StatusNotAcceptable":                 http.StatusNotAcceptable,
+	"StatusProxyAuthRequired":             http.StatusProxyAuthRequired,
+	"StatusRequestTimeout":                http.StatusRequestTimeout,
+	"StatusConflict":                      http.StatusConflict,
+	"StatusGone":                          http.StatusGone,
+	"StatusLengthRequired":                http.StatusLengthRequired,
+	"StatusPreconditionFailed":            http.StatusPreconditionFailed,
+	"StatusRequestEntityTooLarge":         http.StatusRequestEntityTooLarge,
+	"StatusRequestURITooLong":             http.StatusRequestURITooLong,
+	"StatusUnsupportedMediaType":          http.StatusUnsupportedMediaType,
+	"StatusRequestedRangeNotSatisfiable":  http.StatusRequestedRangeNotSatisfiable,
+	"StatusExpectationFailed":             http.StatusExpectationFailed,
+	"StatusTeapot":                        http.StatusTeapot,
+	"StatusMisdirectedRequest":            http.StatusMisdirectedRequest,
+	"StatusUnprocessableEntity":           http.StatusUnprocessableEntity,
+	"StatusLocked":                        http.StatusLocked,
+	"StatusFailedDependency":              http.StatusFailedDependency,
+	"StatusTooEarly":                      http.StatusTooEarly,
+	"StatusUpgradeRequired":               http.StatusUpgradeRequired,
+	"StatusPreconditionRequired":          http.StatusPreconditionRequired,
+	"StatusTooManyRequests":               http.StatusTooManyRequests,
+	"StatusRequestHeaderFieldsTooLarge":   http.StatusRequestHeaderFieldsTooLarge,
+	"StatusUnavailableForLegalReasons":    http.StatusUnavailableForLegalReasons,
+	"StatusInternalServerError":           http.StatusInternalServerError,
+	"StatusNotImplemented":                http.StatusNotImplemented,
+	"StatusBadGateway":                    http.StatusBadGateway,
+	"StatusServiceUnavailable":            http.StatusServiceUnavailable,
+	"StatusGatewayTimeout":                http.StatusGatewayTimeout,
+	"StatusHTTPVersionNotSupported":       http.StatusHTTPVersionNotSupported,
+	"StatusVariantAlsoNegotiates":         http.StatusVariantAlsoNegotiates,
+	"StatusInsufficientStorage":           http.StatusInsufficientStorage,
+	"StatusLoopDetected":                  http.StatusLoopDetected,
+	"StatusNotExtended":                   http.StatusNotExtended,
+	"StatusNetworkAuthenticationRequired": http.StatusNetworkAuthenticationRequired,
+}
+
+type param struct {
+	Key  string
+	Name string
+	Type string
+}
+
+type fieldData struct {
+	Key   string
+	Name  string
+	Param string
+	Type  string
+}
+
+type problemData struct {
+	problems.Definition
+	Name       string
+	TypeName   string
+	TypeConst  string
+	Params     []param
+	Template   []param
+	Fields     []fieldData
+	HasType    bool
+	StatusText string
+}
+
+type fileData struct {
+	Package   string
+	Problems  []problemData
+	HasFields bool
+}
+
+func generate(pkg string, defs []definition) ([]byte, error) {
+	catalog := make([]problems.Definition, 0, len(defs))
+	for _, def := range defs {
+		catalog = append(catalog, def.Definition)
+	}
+	if _, err := problems.NewCatalog(catalog...); err != nil {
+		return nil, err
+	}
+	data := fileData{Package: pkg}
+	// declared maps the generated identifiers to their codes, empty for the helpers
+	declared := map[string]string{"Catalog": "", "Register": "", "contextOptions": "", "assign": ""}
+	for _, def := range defs {
+		p, err := newProblemData(def)
+		if err != nil {
+			return nil, err
+		}
+		for _, ident := range p.identifiers() {
+			if code, ok := declared[ident]; ok {
+				if code == "" {
+					return nil, fmt.Errorf("code '%s' generates %s, which is declared by problemgen", def.Code, ident)
+				}
+				return nil, fmt.Errorf("codes '%s' and '%s' generate the same name %s", code, def.Code, ident)
+			}
+			declared[ident] = def.Code
+		}
+		data.HasFields = data.HasFields || len(p.Fields) > 0
+		data.Problems = append(data.Problems, p)
+	}
+	sort.Slice(data.Problems, func(i, j int) bool {
+		return data.Problems[i].Code < data.Problems[j].Code
+	})
+	buf := &bytes.Buffer{}
+	if err := fileTemplate.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, buf.String())
+	}
+	return code, nil
+}
+
+func newProblemData(def definition) (problemData, error) {
+	p := problemData{
+		Definition: def.Definition,
+		Name:       exportedName(def.Code),
+		HasType:    def.Type != "" && def.Type != problems.DefaultType,
+	}
+	if p.Name == "" {
+		return p, fmt.Errorf("code '%s' does not produce a Go name", def.Code)
+	}
+	p.TypeConst = "Type" + p.Name
+	keys, err := templateParams(def.Detail)
+	if err != nil {
+		return p, fmt.Errorf("invalid detail of code '%s': %w", def.Code, err)
+	}
+	used := map[string]bool{"ctx": true, "err": true, "opts": true}
+	args := map[string]string{}
+	addParam := func(key, typ string) string {
+		if name, ok := args[key]; ok {
+			return name
+		}
+		name := paramName(key, used)
+		args[key] = name
+		p.Params = append(p.Params, param{Key: key, Name: name, Type: typ})
+		return name
+	}
+	for _, key := range keys {
+		typ := def.Params[key]
+		if typ == "" {
+			typ = "string"
+		}
+		p.Template = append(p.Template, param{Key: key, Name: addParam(key, typ), Type: typ})
+	}
+	for k := range def.Params {
+		if _, ok := args[k]; !ok {
+			return p, fmt.Errorf("parameter '%s' of code '%s' is not used in the detail", k, def.Code)
+		}
+	}
+	fieldNames := map[string]bool{}
+	for _, f := range def.Fields {
+		if f.Name == "" || f.Type == "" {
+			return p, fmt.Errorf("field of code '%s' must have a name and a type", def.Code)
+		}
+		name := exportedName(f.Name)
+		if fieldNames[name] || isProblemMember(f.Name) {
+			return p, fmt.Errorf("field '%s' of code '%s' is duplicated", f.Name, def.Code)
+		}
+		fieldNames[name] = true
+		if typ, ok := def.Params[f.Name]; ok && typ != f.Type {
+			return p, fmt.Errorf("field '%s' of code '%s' has type %s but parameter type %s", f.Name, def.Code, f.Type, typ)
+		}
+		p.Fields = append(p.Fields, fieldData{Key: f.Name, Name: name, Param: addParam(f.Name, f.Type), Type: f.Type})
+	}
+	if len(p.Fields) > 0 {
+		p.TypeName = p.Name + "Problem"
+	}
+	return p, nil
+}
+
+// identifiers returns the package-level identifiers generated for the problem.
+func (p problemData) identifiers() []string {
+	idents := []string{"Code" + p.Name, p.Name, "Wrap" + p.Name}
+	if p.HasType {
+		idents = append(idents, p.TypeConst)
+	}
+	if p.TypeName != "" {
+		idents = append(idents, p.TypeName, "with"+p.Name)
+	}
+	return idents
+}
+
+func isProblemMember(name string) bool {
+	switch name {
+	case "type", "title", "status", "detail", "instance", "code":
+		return true
+	}
+	return false
+}
+
+// templateParams returns the top-level fields referenced by the template, in order of appearance.
+func templateParams(text string) ([]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New("").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			add(n.Ident[0])
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+		case *parse.WithNode:
+			walk(n.Pipe)
+		}
+	}
+	walk(t.Tree.Root)
+	return keys, nil
+}
+
+// exportedName converts codes such as USER_NOT_FOUND or user-not-found into UserNotFound.
+func exportedName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	buf := strings.Builder{}
+	for _, part := range parts {
+		runes := []rune(part)
+		if strings.ToUpper(part) == part {
+			runes = []rune(strings.ToLower(part))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	name := buf.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "P" + name
+	}
+	return name
+}
+
+func paramName(key string, used map[string]bool) string {
+	name := exportedName(key)
+	if name == "" {
+		name = "Param"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	name = string(runes)
+	if token.IsKeyword(name) || used[name] {
+		name += "Value"
+	}
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+	}
+	used[name] = true
+	return name
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by problemgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+{{- if .HasFields}}
+	"encoding/json"
+{{- end}}
+	"errors"
+{{- if .HasFields}}
+	"net/http"
+{{- end}}
+
+	"github.com/goccha/problems"
+)
+
+const (
+{{- range .Problems}}
+	Code{{.Name}} = {{printf "%q" .Code}}
+{{- end}}
+)
+{{- if .Problems}}
+
+const (
+{{- range .Problems}}{{if .HasType}}
+	{{.TypeConst}} = {{printf "%q" .Type}}
+{{- end}}{{end}}
+)
+{{- end}}
+
+// Catalog holds the generated problem definitions.
+var Catalog = func() *problems.Catalog {
+	c, err := problems.NewCatalog(
+{{- range .Problems}}
+		problems.Definition{Code: Code{{.Name}}, {{if .HasType}}Type: {{.TypeConst}}, {{else if .Type}}Type: {{printf "%q" .Type}}, {{end}}Status: {{.Status}}{{if .Title}}, Title: {{printf "%q" .Title}}{{end}}{{if .Detail}}, Detail: {{printf "%q" .Detail}}{{end}}},
+{{- end}}
+	)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}()
+
+// Register registers the problem types of the catalog.
+func Register(r *problems.Registry) error {
+	return errors.Join(
+{{- range .Problems}}{{if .HasType}}
+		r.Register({{.TypeConst}}, func() problems.Problem {
+			return {{if .TypeName}}&{{.TypeName}}{DefaultProblem: &problems.DefaultProblem{}}{{else}}&problems.DefaultProblem{}{{end}}
+		}, problems.DefaultStatus({{.Status}}){{if .Title}}, problems.DefaultTitle({{printf "%q" .Title}}){{end}}),
+{{- end}}{{end}}
+	)
+}
+
+func contextOptions(ctx context.Context, opts []problems.Option) []problems.Option {
+	if ref := problems.ReferenceFrom(ctx); ref != "" {
+		return append([]problems.Option{problems.Reference(ref)}, opts...)
+	}
+	return opts
+}
+{{range .Problems}}
+// {{.Name}} returns the {{.Code}} problem.
+func {{.Name}}(ctx context.Context, {{range .Params}}{{.Name}} {{.Type}}, {{end}}opts ...problems.Option) problems.Problem {
+	opts = contextOptions(ctx, opts)
+{{- if .TypeName}}
+	opts = append([]problems.Option{with{{.Name}}({{range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f.Param}}{{end}})}, opts...)
+{{- end}}
+	return Catalog.New(Code{{.Name}}, {{if .Template}}map[string]interface{}{ {{- range $i, $p := .Template}}{{if $i}}, {{end}}{{printf "%q" $p.Key}}: {{$p.Name}}{{end}}}{{else}}nil{{end}}, opts...)
+}
+
+// Wrap{{.Name}} returns the {{.Code}} problem as an error wrapping err.
+func Wrap{{.Name}}(ctx context.Context, err error, {{range .Params}}{{.Name}} {{.Type}}, {{end}}opts ...problems.Option) error {
+	return {{.Name}}(ctx, {{range .Params}}{{.Name}}, {{end}}append(opts, problems.Wrap(err))...).Wrap()
+}
+{{- if .TypeName}}
+
+// {{.TypeName}} is the {{.Code}} problem with its extension members.
+type {{.TypeName}} struct {
+	*problems.DefaultProblem
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`json:\"{{.Key}},omitempty\"`" + `
+{{- end}}
+}
+
+func with{{.Name}}({{range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f.Param}} {{$f.Type}}{{end}}) problems.Option {
+	return func(p problems.DefaultParams) problems.Problem {
+		if dp, ok := p.(*problems.DefaultProblem); ok {
+			return &{{.TypeName}}{DefaultProblem: dp{{range .Fields}}, {{.Name}}: {{.Param}}{{end}}}
+		}
+		return p.(problems.Problem)
+	}
+}
+
+func (p *{{.TypeName}}) JSON(ctx context.Context, w http.ResponseWriter) {
+	problems.WriteJson(ctx, w, p.ProblemStatus(), p)
+}
+func (p *{{.TypeName}}) XML(ctx context.Context, w http.ResponseWriter) {
+	problems.WriteXml(ctx, w, p.ProblemStatus(), p)
+}
+func (p *{{.TypeName}}) GraphQL(ctx context.Context, w http.ResponseWriter) {
+	problems.WriteGraphQL(ctx, w, p.ProblemStatus(), p)
+}
+func (p *{{.TypeName}}) Wrap() error {
+	return problems.WrapProblem(p)
+}
+func (p *{{.TypeName}}) String() string {
+	bin, _ := problems.Marshal(p)
+	return string(bin)
+}
+func (p *{{.TypeName}}) Encode() problems.GraphQLError {
+	err := p.DefaultProblem.Encode()
+{{- range .Fields}}
+	err.Extensions[{{printf "%q" .Key}}] = p.{{.Name}}
+{{- end}}
+	return err
+}
+func (p *{{.TypeName}}) Decode(err problems.GraphQLError) problems.Problem {
+	if p.DefaultProblem == nil {
+		p.DefaultProblem = &problems.DefaultProblem{}
+	}
+	p.DefaultProblem.Decode(err)
+{{- range .Fields}}
+	if v, ok := err.Extensions[{{printf "%q" .Key}}]; ok {
+		assign(v, &p.{{.Name}})
+		delete(p.Extensions(), {{printf "%q" .Key}})
+	}
+{{- end}}
+	return p
+}
+{{- end}}
+{{end}}
+{{- if .HasFields}}
+// assign converts a decoded extension member into the type of dst.
+func assign(v interface{}, dst interface{}) {
+	if bin, err := json.Marshal(v); err == nil {
+		_ = json.Unmarshal(bin, dst)
+	}
+}
+{{- end}}
+`))
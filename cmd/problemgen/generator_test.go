@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const catalogJSON = `[
+	{"code": "USER_NOT_FOUND", "type": "https://example.com/probs/user-not-found", "status": 404, "title": "User not found", "detail": "user {{.id}} does not exist"},
+	{"code": "OUT_OF_CREDIT", "type": "https://example.com/probs/out-of-credit", "status": 403, "title": "You do not have enough credit.",
+	 "detail": "Your current balance is {{.balance}}{{if .type}} ({{.type}}){{end}}", "params": {"balance": "int"},
+	 "fields": [{"name": "balance", "type": "int"}, {"name": "accounts", "type": "[]string"}]},
+	{"code": "maintenance", "status": 503}
+]`
+
+const catalogGo = `package errs
+
+import (
+	"net/http"
+
+	"github.com/goccha/problems"
+)
+
+const baseURI = "https://example.com/probs/"
+
+var defs = []problems.Definition{
+	{Code: "USER_NOT_FOUND", Type: baseURI + "user-not-found", Status: http.StatusNotFound, Title: "User not found", Detail: "user {{.id}} does not exist"},
+	// balance is kept as an extension member
+	//problemgen:param balance int
+	//problemgen:field balance int
+	//problemgen:field accounts []string
+	{Code: "OUT_OF_CREDIT", Type: baseURI + "out-of-credit", Status: 403, Detail: "Your current balance is {{.balance}}"},
+	problems.Definition{Code: "maintenance", Status: 503},
+}
+`
+
+func TestGenerate(t *testing.T) {
+	defs, err := parseJSON([]byte(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := generate("errs", defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "problems_gen.go", code, 0); err != nil {
+		t.Fatal(err)
+	}
+	src := string(code)
+	for _, s := range []string{
+		"// Code generated by problemgen. DO NOT EDIT.",
+		"package errs",
+		`CodeUserNotFound = "USER_NOT_FOUND"`,
+		`TypeOutOfCredit  = "https://example.com/probs/out-of-credit"`,
+		"func UserNotFound(ctx context.Context, id string, opts ...problems.Option) problems.Problem",
+		"func OutOfCredit(ctx context.Context, balance int, typeValue string, accounts []string, opts ...problems.Option) problems.Problem",
+		`map[string]interface{}{"balance": balance, "type": typeValue}`,
+		"func Maintenance(ctx context.Context, opts ...problems.Option) problems.Problem",
+		"func WrapUserNotFound(ctx context.Context, err error, id string, opts ...problems.Option) error",
+		"func WrapOutOfCredit(ctx context.Context, err error, balance int, typeValue string, accounts []string, opts ...problems.Option) error",
+		"Accounts []string `json:\"accounts,omitempty\"`",
+		"func (p *OutOfCreditProblem) Encode() problems.GraphQLError",
+		"func (p *OutOfCreditProblem) Decode(err problems.GraphQLError) problems.Problem",
+		"func Register(r *problems.Registry) error",
+	} {
+		if !strings.Contains(src, s) {
+			t.Errorf("expect = %v, actual = %v", s, src)
+		}
+	}
+	if strings.Contains(src, "TypeMaintenance") {
+		t.Errorf("expect = %v, actual = %v", "no TypeMaintenance", src)
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	tests := []string{
+		`[{"code": "A", "status": 200}]`,
+		`[{"code": "A", "status": 400, "params": {"id": "int"}}]`,
+		`[{"code": "A", "status": 400, "fields": [{"name": "status", "type": "int"}]}]`,
+		`[{"code": "a-b", "status": 400}, {"code": "A_B", "status": 400}]`,
+		`[{"code": "A", "status": 400, "detail": "{{.id}}", "params": {"id": "int"}, "fields": [{"name": "id", "type": "string"}]}]`,
+		`[{"code": "catalog", "status": 400}]`,
+		`[{"code": "A", "status": 400}, {"code": "CODE_A", "status": 400}]`,
+		`[{"code": "A", "status": 400, "fields": [{"name": "x", "type": "int"}]}, {"code": "A_PROBLEM", "status": 400}]`,
+	}
+	for _, s := range tests {
+		defs, err := parseJSON([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = generate("errs", defs); err == nil {
+			t.Errorf("expect = %v, actual = %v", "error", s)
+		}
+	}
+	if _, err := parseJSON([]byte(`[{"code": "A", "status": 400, "unknown": 1}]`)); err == nil {
+		t.Errorf("expect = %v, actual = %v", "error", err)
+	}
+}
+
+func TestParseGo(t *testing.T) {
+	defs, err := parseGo("defs.go", []byte(catalogGo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 3 {
+		t.Fatalf("expect = %v, actual = %v", 3, len(defs))
+	}
+	if defs[0].Code != "USER_NOT_FOUND" || defs[0].Status != 404 || defs[0].Type != "https://example.com/probs/user-not-found" || defs[0].Detail != "user {{.id}} does not exist" {
+		t.Errorf("expect = %v, actual = %v", "USER_NOT_FOUND", defs[0])
+	}
+	expect := []field{{Name: "balance", Type: "int"}, {Name: "accounts", Type: "[]string"}}
+	if defs[1].Params["balance"] != "int" || !reflect.DeepEqual(defs[1].Fields, expect) {
+		t.Errorf("expect = %v, actual = %v", expect, defs[1])
+	}
+	if defs[2].Code != "maintenance" || defs[2].Status != 503 {
+		t.Errorf("expect = %v, actual = %v", "maintenance", defs[2])
+	}
+	for _, src := range []string{
+		`var d = Definition{Code: code, Status: 400}`,
+		`var d = Definition{Code: "A", Status: http.StatusNotFound}`,
+		`var d = Definition{Code: "A" + 1, Status: 400}`,
+		`const a = b; const b = a; var d = Definition{Code: a, Status: 400}`,
+		"//problemgen:param id\nvar d = Definition{Code: \"A\", Status: 400}",
+		"//problemgen:unknown id int\nvar d = Definition{Code: \"A\", Status: 400}",
+	} {
+		if _, err = parseGo("defs.go", []byte("package errs\n"+src)); err == nil {
+			t.Errorf("expect = %v, actual = %v", "error", src)
+		}
+	}
+}
+
+// wrapTest checks that the generated Wrap functions keep the cause and the problem; %s is the arguments of WrapOutOfCredit.
+const wrapTest = `package errs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goccha/problems"
+)
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("cause")
+	if err := WrapUserNotFound(context.TODO(), cause, "1"); !errors.Is(err, cause) {
+		t.Errorf("expect = %%v, actual = %%v", cause, err)
+	}
+	err := WrapOutOfCredit(context.TODO(), cause, %s)
+	var pe *problems.ProblemError
+	if !errors.Is(err, cause) || !errors.As(err, &pe) {
+		t.Fatalf("expect = %%v, actual = %%v", cause, err)
+	}
+	if p, ok := pe.Problem().(*OutOfCreditProblem); !ok || p.Balance != 30 {
+		t.Errorf("expect = %%v, actual = %%v", 30, pe.Problem())
+	}
+}
+`
+
+// TestGenerate_Build builds and tests the generated code in a temporary module using this repository.
+func TestGenerate_Build(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonDefs, err := parseJSON([]byte(catalogJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goDefs, err := parseGo("defs.go", []byte(catalogGo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		defs []definition
+		args string
+	}{
+		{name: "json", defs: jsonDefs, args: `30, "", nil`},
+		{name: "go", defs: goDefs, args: `30, nil`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		code, err := generate("errs", tt.defs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mod := "module example.com/errs\n\ngo 1.22\n\nrequire github.com/goccha/problems v0.0.0\n\nreplace github.com/goccha/problems => " + root + "\n"
+		for file, content := range map[string][]byte{
+			"go.mod":               []byte(mod),
+			"go.sum":               sum,
+			"problems_gen.go":      code,
+			"problems_gen_test.go": []byte(fmt.Sprintf(wrapTest, tt.args)),
+		} {
+			if err = os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, args := range [][]string{{"vet", "."}, {"test", "."}} {
+			cmd := exec.Command(gobin, args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("%s: go %s: %v\n%s", tt.name, args[0], err, out)
+			}
+		}
+	}
+}
+
+func TestTemplateParams(t *testing.T) {
+	keys, err := templateParams("{{.a}} {{.b.c}} {{if .d}}{{.e}}{{end}} {{range .f}}{{.g}}{{end}} {{.a}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"a", "b", "d", "e", "f"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("expect = %v, actual = %v", expect, keys)
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"USER_NOT_FOUND": "UserNotFound",
+		"user-not-found": "UserNotFound",
+		"outOfCredit":    "OutOfCredit",
+		"404_page":       "P404Page",
+	}
+	for code, expect := range tests {
+		if actual := exportedName(code); actual != expect {
+			t.Errorf("expect = %v, actual = %v", expect, actual)
+		}
+	}
+}
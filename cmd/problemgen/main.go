@@ -0,0 +1,93 @@
+// Command problemgen generates typed constructors, constants, custom problem types and
+// a registration function from a problem catalog.
+//
+// The catalog is either a JSON array of definitions, which may declare the types of detail
+// template parameters and custom extension fields:
+//
+//	[
+//	  {"code": "OUT_OF_CREDIT", "type": "https://example.com/probs/out-of-credit", "status": 403,
+//	   "title": "You do not have enough credit.", "detail": "Your current balance is {{.balance}}",
+//	   "params": {"balance": "int"}, "fields": [{"name": "balance", "type": "int"}]}
+//	]
+//
+// or a Go file containing problems.Definition composite literals. Their values are literals,
+// constants declared in the file, net/http status constants or concatenations of them, and the
+// types of parameters and fields are declared by directives preceding a literal:
+//
+//	//problemgen:param balance int
+//	//problemgen:field balance int
+//	{Code: "OUT_OF_CREDIT", Type: baseURI + "out-of-credit", Status: http.StatusForbidden, ...},
+//
+// Usage:
+//
+//	problemgen -in problems.json -out problems_gen.go -package errs
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "catalog file (.json or .go)")
+	out := flag.String("out", "", "output file (default: stdout)")
+	pkg := flag.String("package", "", "package name of the generated file (default: directory name of -out)")
+	flag.Parse()
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "problemgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string) error {
+	src, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	var defs []definition
+	if strings.HasSuffix(in, ".go") {
+		defs, err = parseGo(in, src)
+	} else {
+		defs, err = parseJSON(src)
+	}
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = "problems"
+		if out != "" {
+			if abs, err := filepath.Abs(out); err == nil {
+				pkg = sanitizePackage(filepath.Base(filepath.Dir(abs)))
+			}
+		}
+	}
+	code, err := generate(pkg, defs)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(out, code, 0o644)
+}
+
+func sanitizePackage(name string) string {
+	name = strings.ToLower(strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" {
+		return "problems"
+	}
+	return name
+}
@@ -0,0 +1,427 @@
+package problems
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var memberCache sync.Map
+
+// jsonMembers returns the JSON member names of the struct type t, together with their Go types.
+func jsonMembers(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v, ok := memberCache.Load(t); ok {
+		return v.(map[string]reflect.Type)
+	}
+	members := make(map[string]reflect.Type)
+	if t.Kind() == reflect.Struct {
+		collectMembers(t, members)
+	}
+	memberCache.Store(t, members)
+	return members
+}
+
+func collectMembers(t reflect.Type, members map[string]reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := members[name]; !ok {
+			members[name] = f.Type
+		}
+	}
+	for _, ft := range embedded {
+		collectMembers(ft, members)
+	}
+}
+
+// Marshal encodes a problem as JSON, including its extension members.
+// Problems embedding *DefaultProblem use it to implement String.
+func Marshal(v interface{}) ([]byte, error) {
+	return marshalJSON(v)
+}
+
+// marshalJSON encodes v and flattens its extension members into the top-level object.
+func marshalJSON(v interface{}) ([]byte, error) {
+	bin, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if ep, ok := v.(ExtensionParameter); ok {
+		return appendExtensions(bin, jsonMembers(reflect.TypeOf(v)), ep.Extensions())
+	}
+	return bin, nil
+}
+
+func extensionKeys(members map[string]reflect.Type, ext map[string]interface{}) []string {
+	keys := make([]string, 0, len(ext))
+	for k := range ext {
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func appendExtensions(bin []byte, members map[string]reflect.Type, ext map[string]interface{}) ([]byte, error) {
+	keys := extensionKeys(members, ext)
+	if len(keys) == 0 || len(bin) < 2 || bin[len(bin)-1] != '}' {
+		return bin, nil
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(bin)+32*len(keys)))
+	buf.Write(bin[:len(bin)-1])
+	empty := bytes.Equal(bytes.TrimSpace(bin), []byte("{}"))
+	for i, k := range keys {
+		if !empty || i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(ext[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSON decodes data into v and collects unknown members as extension members.
+func unmarshalJSON(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	ep, ok := v.(ExtensionParameter)
+	if !ok {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	members := jsonMembers(reflect.TypeOf(v))
+	for k, r := range raw {
+		if _, ok := members[k]; ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(r, &value); err != nil {
+			return err
+		}
+		ep.SetExtension(k, value)
+	}
+	return nil
+}
+
+// marshalXML encodes v in the XML format of RFC7807 Appendix A, converting its JSON representation
+// into elements of a namespaced <problem> root and arrays into <i> items.
+func marshalXML(v interface{}) ([]byte, error) {
+	bin, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bin))
+	dec.UseNumber()
+	buf := bytes.NewBufferString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	root := xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: XmlNamespace}},
+	}
+	if err = encodeXMLValue(dec, enc, root); err != nil {
+		return nil, err
+	}
+	if err = enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeXMLValue(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err = enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		for dec.More() {
+			name := "i"
+			if value == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				name = key.(string)
+				if !isXMLName(name) {
+					return fmt.Errorf("problems: member '%s' is not a valid XML element name", name)
+				}
+			}
+			if err = encodeXMLValue(dec, enc, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = enc.EncodeToken(xml.CharData(value))
+	case json.Number:
+		err = enc.EncodeToken(xml.CharData(value.String()))
+	case bool:
+		err = enc.EncodeToken(xml.CharData(strconv.FormatBool(value)))
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// isXMLName reports whether name matches the Name production of XML 1.0 without colons.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isNameStartChar(r) && (i == 0 || !isNameChar(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStartChar(r rune) bool {
+	return r == '_' || 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' ||
+		0xC0 <= r && r <= 0xD6 || 0xD8 <= r && r <= 0xF6 || 0xF8 <= r && r <= 0x2FF ||
+		0x370 <= r && r <= 0x37D || 0x37F <= r && r <= 0x1FFF || 0x200C <= r && r <= 0x200D ||
+		0x2070 <= r && r <= 0x218F || 0x2C00 <= r && r <= 0x2FEF || 0x3001 <= r && r <= 0xD7FF ||
+		0xF900 <= r && r <= 0xFDCF || 0xFDF0 <= r && r <= 0xFFFD || 0x10000 <= r && r <= 0xEFFFF
+}
+
+func isNameChar(r rune) bool {
+	return r == '-' || r == '.' || '0' <= r && r <= '9' || r == 0xB7 ||
+		0x300 <= r && r <= 0x36F || 0x203F <= r && r <= 0x2040
+}
+
+type xmlNode struct {
+	name     string
+	space    string
+	text     strings.Builder
+	children []*xmlNode
+}
+
+func isXML(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) > 0 && body[0] == '<'
+}
+
+func parseXML(body []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, space: t.Name.Space}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("problems: empty xml document")
+	}
+	return root, nil
+}
+
+// checkProblem requires the element to be a problem root element of RFC7807 Appendix A.
+func (n *xmlNode) checkProblem() error {
+	if n.name != "problem" || n.space != XmlNamespace {
+		return fmt.Errorf("problems: root element must be problem in namespace %s, actual = {%s}%s", XmlNamespace, n.space, n.name)
+	}
+	return nil
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) value() string {
+	return strings.TrimSpace(n.text.String())
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// toJSON converts the element into a JSON value, guided by the Go type it will be decoded into.
+func (n *xmlNode) toJSON(t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return n.generic()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return n.value()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		members := jsonMembers(t)
+		obj := make(map[string]interface{}, len(n.children))
+		for _, c := range n.children {
+			if name, ft, ok := lookupMember(members, c.name); ok {
+				obj[name] = c.toJSON(ft)
+			} else {
+				obj[c.name] = c.generic()
+			}
+		}
+		return obj
+	case reflect.Map:
+		obj := make(map[string]interface{}, len(n.children))
+		for _, c := range n.children {
+			obj[c.name] = c.toJSON(t.Elem())
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return n.value()
+		}
+		list := make([]interface{}, 0, len(n.children))
+		for _, c := range n.children {
+			list = append(list, c.toJSON(t.Elem()))
+		}
+		return list
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(n.value()); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(n.value(), 64); err == nil {
+			return json.Number(n.value())
+		}
+	}
+	return n.value()
+}
+
+// generic converts the element without type information; leaf values are strings.
+func (n *xmlNode) generic() interface{} {
+	if len(n.children) == 0 {
+		return n.value()
+	}
+	items := true
+	for _, c := range n.children {
+		if c.name != "i" {
+			items = false
+			break
+		}
+	}
+	if items {
+		list := make([]interface{}, 0, len(n.children))
+		for _, c := range n.children {
+			list = append(list, c.generic())
+		}
+		return list
+	}
+	obj := make(map[string]interface{}, len(n.children))
+	for _, c := range n.children {
+		obj[c.name] = c.generic()
+	}
+	return obj
+}
+
+func lookupMember(members map[string]reflect.Type, name string) (string, reflect.Type, bool) {
+	if t, ok := members[name]; ok {
+		return name, t, true
+	}
+	for k, t := range members {
+		if strings.EqualFold(k, name) {
+			return k, t, true
+		}
+	}
+	return "", nil, false
+}
+
+// unmarshalXML decodes a problem+xml document into v.
+func unmarshalXML(data []byte, v interface{}) error {
+	root, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	if err = root.checkProblem(); err != nil {
+		return err
+	}
+	bin, err := json.Marshal(root.toJSON(reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return unmarshalJSON(bin, v)
+}
+
+// unmarshalProblem decodes a problem+json or problem+xml document into v.
+func unmarshalProblem(data []byte, v interface{}) error {
+	if isXML(data) {
+		return unmarshalXML(data, v)
+	}
+	return unmarshalJSON(data, v)
+}
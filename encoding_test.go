@@ -0,0 +1,126 @@
+package problems
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBadRequest_XML(t *testing.T) {
+	p := New(Instance("/users"), Code("E001"), InvalidParams(nil, InvalidParam{Name: "age", Reason: "must be a positive integer"}),
+		ValidationErrors(nil, ValidationError{Detail: "required", Pointer: "#/name"})).BadRequest("bad request")
+	w := httptest.NewRecorder()
+	p.XML(context.TODO(), w)
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Bad Request</title><status>400</status>` +
+		`<detail>bad request</detail><instance>/users</instance><code>E001</code>` +
+		`<invalid-params><i><name>age</name><reason>must be a positive integer</reason></i></invalid-params>` +
+		`<errors><i><detail>required</detail><pointer>#/name</pointer></i></errors></problem>`
+	if actual := w.Body.String(); actual != expect {
+		t.Errorf("expect = %s, actual = %s", expect, actual)
+	}
+}
+
+func TestDecode_XML(t *testing.T) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<problem xmlns="urn:ietf:rfc:7807">
+  <type>about:blank</type>
+  <title>Bad Request</title>
+  <status>400</status>
+  <detail>bad request</detail>
+  <invalid-params>
+    <i><name>age</name><reason>must be a positive integer</reason></i>
+  </invalid-params>
+  <errors>
+    <i><detail>required</detail><pointer>#/name</pointer></i>
+  </errors>
+  <trace><i>a</i><i>b</i></trace>
+</problem>`
+	p, err := Decode(context.TODO(), http.StatusBadRequest, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	br, ok := p.(*BadRequest)
+	if !ok {
+		t.Fatalf("expect = BadRequest, actual = %v", p)
+	}
+	if br.Status != http.StatusBadRequest {
+		t.Errorf("expect = %d, actual = %d", http.StatusBadRequest, br.Status)
+	}
+	if br.Detail != "bad request" {
+		t.Errorf("expect = bad request, actual = %s", br.Detail)
+	}
+	if len(br.InvalidParams) != 1 || br.InvalidParams[0].Name != "age" {
+		t.Errorf("expect = age, actual = %v", br.InvalidParams)
+	}
+	if len(br.Errors) != 1 || br.Errors[0].Pointer != "#/name" {
+		t.Errorf("expect = #/name, actual = %v", br.Errors)
+	}
+	if trace, ok := br.Extensions()["trace"].([]interface{}); !ok || len(trace) != 2 {
+		t.Errorf("expect = [a b], actual = %v", br.Extensions()["trace"])
+	}
+}
+
+func TestBind_XMLRegisteredType(t *testing.T) {
+	defer useOutOfCreditRegistry()()
+	p := &OutOfCredit{DefaultProblem: NewProblem(http.StatusForbidden), Balance: 30, Accounts: []string{"/account/12345"}}
+	p.Type = outOfCreditType
+	bin, err := marshalXML(p)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	bp, err := Bind(context.TODO(), http.StatusForbidden, bin)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if oc, ok := bp.(*OutOfCredit); !ok {
+		t.Errorf("expect = OutOfCredit, actual = %v", bp)
+	} else if oc.Balance != 30 || len(oc.Accounts) != 1 {
+		t.Errorf("expect = 30 [/account/12345], actual = %d %v", oc.Balance, oc.Accounts)
+	}
+}
+
+func TestMarshalXML_InvalidName(t *testing.T) {
+	for _, name := range []string{"foo bar", "1x", "a:b", "<x>"} {
+		if _, err := marshalXML(New(Extension(name, "v")).NotFound("")); err == nil {
+			t.Errorf("expect = error, actual = nil (%s)", name)
+		}
+	}
+	if _, err := marshalXML(New(Extension("x-1.ok_é", "v")).NotFound("")); err != nil {
+		t.Errorf("expect = nil, actual = %v", err)
+	}
+	w := httptest.NewRecorder()
+	New(Extension("foo bar", "v")).NotFound("missing").XML(context.TODO(), w)
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Not Found</title><status>404</status><detail>missing</detail></problem>`
+	if w.Code != http.StatusNotFound || w.Body.String() != expect {
+		t.Errorf("expect = %v, actual = %v", expect, w.Body.String())
+	}
+}
+
+func TestWriteXml_Struct(t *testing.T) {
+	type message struct {
+		XMLName xml.Name `xml:"message"`
+		Text    string   `xml:"text,attr"`
+	}
+	w := httptest.NewRecorder()
+	WriteXml(context.TODO(), w, http.StatusBadRequest, &message{Text: "hello"})
+	if expect := `<message text="hello"></message>`; w.Body.String() != expect {
+		t.Errorf("expect = %v, actual = %v", expect, w.Body.String())
+	}
+}
+
+func TestDecode_XMLRoot(t *testing.T) {
+	for _, body := range []string{
+		`<html><head><title>502 Bad Gateway</title></head><body><center>nginx</center></body></html>`,
+		`<problem><title>no namespace</title></problem>`,
+		`<error xmlns="urn:ietf:rfc:7807"><title>wrong root</title></error>`,
+	} {
+		if _, err := Decode(context.TODO(), http.StatusBadGateway, strings.NewReader(body)); err == nil {
+			t.Errorf("expect = error, actual = nil (%s)", body)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 
 	"github.com/goccha/http-constants/pkg/mimetypes"
-	"github.com/goccha/logging/log"
 )
 
 type GraphQLRenderer interface {
@@ -80,6 +79,13 @@ func (err *GraphQLError) Decode(ext GraphQLExtension) Problem {
 }
 
 func (err *GraphQLError) Problem() Problem {
+	if typ, ok := err.Extensions["type"].(string); ok {
+		p := DefaultRegistry.Create(typ)
+		if ext, ok := p.(GraphQLExtension); ok {
+			ext.Decode(*err)
+			return p
+		}
+	}
 	switch err.ProblemStatus() {
 	case http.StatusBadRequest:
 		return err.Decode(&BadRequest{})
@@ -113,6 +119,11 @@ func (p *DefaultProblem) Encode() GraphQLError {
 	if p.Code != "" {
 		err.Extensions["code"] = p.Code
 	}
+	for k, v := range p.extensions {
+		if _, ok := err.Extensions[k]; !ok {
+			err.Extensions[k] = v
+		}
+	}
 	return err
 }
 func (p *DefaultProblem) Decode(err GraphQLError) Problem {
@@ -135,6 +146,13 @@ func (p *DefaultProblem) Decode(err GraphQLError) Problem {
 	if err.Extensions["code"] != nil {
 		p.Code = err.Extensions["code"].(string)
 	}
+	for k, v := range err.Extensions {
+		switch k {
+		case "type", "title", "status", "instance", "code":
+		default:
+			p.SetExtension(k, v)
+		}
+	}
 	return p
 }
 func (p *DefaultProblem) GraphQL(ctx context.Context, w http.ResponseWriter) {
@@ -142,7 +160,7 @@ func (p *DefaultProblem) GraphQL(ctx context.Context, w http.ResponseWriter) {
 }
 
 func WriteGraphQL(ctx context.Context, w http.ResponseWriter, status int, v interface{}) {
-	setHeader(ctx, w, status, mimetypes.JSON)
+	setHeader(ctx, w, status, mimetypes.JSON, v)
 	res := &GraphQLResponse{}
 	if encoder, ok := v.(GraphQLExtension); ok {
 		err := encoder.Encode()
@@ -151,7 +169,7 @@ func WriteGraphQL(ctx context.Context, w http.ResponseWriter, status int, v inte
 		res.Data = v
 	}
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		log.EmbedObject(ctx, log.Warn(ctx).Err(err)).Send()
+		loggerOf(v).Log(ctx, Entry{Level: LevelWarn, Err: err})
 	}
 }
 
@@ -180,7 +198,12 @@ func (p *BadRequest) Encode() GraphQLError {
 	return err
 }
 func (p *BadRequest) Decode(err GraphQLError) Problem {
+	if p.DefaultProblem == nil {
+		p.DefaultProblem = &DefaultProblem{}
+	}
 	p.DefaultProblem.Decode(err)
+	delete(p.extensions, "invalid-params")
+	delete(p.extensions, "errors")
 	if v, ok := err.Extensions["invalid-params"]; ok {
 		params := v.([]map[string]interface{})
 		for _, param := range params {
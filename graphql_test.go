@@ -64,3 +64,24 @@ func TestDefaultProblemDecode(t *testing.T) {
 		t.Errorf("expect = error-code, actual = %s", problem.Code)
 	}
 }
+
+func TestDefaultProblemExtensions(t *testing.T) {
+	problem := &DefaultProblem{Type: "type", Title: "title", Status: 403}
+	problem.SetExtension("balance", 30)
+	problem.SetExtension("type", "ignored")
+	err := problem.Encode()
+	if err.Extensions["balance"] != 30 {
+		t.Errorf("expect = 30, actual = %v", err.Extensions["balance"])
+	}
+	if err.Extensions["type"] != "type" {
+		t.Errorf("expect = type, actual = %v", err.Extensions["type"])
+	}
+	decoded := &DefaultProblem{}
+	decoded.Decode(err)
+	if decoded.Extensions()["balance"] != 30 {
+		t.Errorf("expect = 30, actual = %v", decoded.Extensions()["balance"])
+	}
+	if _, ok := decoded.Extensions()["title"]; ok {
+		t.Errorf("expect = no title extension, actual = %v", decoded.Extensions())
+	}
+}
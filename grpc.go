@@ -0,0 +1,241 @@
+package problems
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	RetryAfterKey = "retry-after"
+)
+
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:                   codes.InvalidArgument,
+	http.StatusUnauthorized:                 codes.Unauthenticated,
+	http.StatusForbidden:                    codes.PermissionDenied,
+	http.StatusNotFound:                     codes.NotFound,
+	http.StatusRequestTimeout:               codes.DeadlineExceeded,
+	http.StatusConflict:                     codes.Aborted,
+	http.StatusGone:                         codes.NotFound,
+	http.StatusPreconditionFailed:           codes.FailedPrecondition,
+	http.StatusRequestedRangeNotSatisfiable: codes.OutOfRange,
+	http.StatusUnprocessableEntity:          codes.InvalidArgument,
+	http.StatusTooManyRequests:              codes.ResourceExhausted,
+	499:                                     codes.Canceled,
+	http.StatusInternalServerError:          codes.Internal,
+	http.StatusNotImplemented:               codes.Unimplemented,
+	http.StatusBadGateway:                   codes.Unavailable,
+	http.StatusServiceUnavailable:           codes.Unavailable,
+	http.StatusGatewayTimeout:               codes.DeadlineExceeded,
+}
+
+var statusMu sync.RWMutex
+
+// httpStatuses follows the HTTP mapping of google.rpc.Code.
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// GRPCCode returns the gRPC code corresponding to the HTTP status.
+func GRPCCode(status int) codes.Code {
+	if code, ok := grpcCodes[status]; ok {
+		return code
+	}
+	switch {
+	case status >= 500:
+		return codes.Internal
+	case status >= 400:
+		return codes.FailedPrecondition
+	}
+	return codes.Unknown
+}
+
+// HTTPStatus returns the HTTP status corresponding to the gRPC code.
+func HTTPStatus(code codes.Code) int {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// SetHTTPStatus overrides the HTTP status used by Of and FromStatus for the gRPC code in the whole process.
+// Use Mapper.SetHTTPStatus to override it for a mapper only.
+func SetHTTPStatus(code codes.Code, status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	httpStatuses[code] = status
+}
+
+// ToStatus converts the problem into a gRPC status carrying ErrorInfo, BadRequest and RetryInfo details.
+func ToStatus(p Problem) *status.Status {
+	if p == nil {
+		return nil
+	}
+	code := GRPCCode(p.ProblemStatus())
+	dp := defaultProblemOf(p)
+	if dp == nil {
+		return status.New(code, p.String())
+	}
+	msg := dp.Detail
+	if msg == "" {
+		msg = dp.Title
+	}
+	st := status.New(code, msg)
+	info := &errdetails.ErrorInfo{
+		Reason:   dp.Code,
+		Metadata: map[string]string{},
+	}
+	for k, v := range map[string]string{"type": dp.Type, "title": dp.Title, "instance": dp.Instance, "code": dp.Code} {
+		if v != "" {
+			info.Metadata[k] = v
+		}
+	}
+	if dp.Status > 0 {
+		info.Metadata["status"] = strconv.Itoa(dp.Status)
+	}
+	if ref := referenceOf(p); ref != "" {
+		info.Metadata[ReferenceKey] = ref
+	}
+	details := []protoadapt.MessageV1{info}
+	if br := badRequestOf(p); br != nil && (len(br.Errors) > 0 || len(br.InvalidParams) > 0) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(br.Errors)+len(br.InvalidParams))
+		for _, v := range br.Errors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: v.Pointer, Description: v.Detail})
+		}
+		for _, v := range br.InvalidParams {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: v.Name, Description: v.Reason})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if d, ok := dp.RetryAfter(); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	} else if d, ok := retryDelay(dp.Extensions()[RetryAfterKey]); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	if ds, err := st.WithDetails(details...); err == nil {
+		st = ds
+	}
+	return st
+}
+
+// FromStatus reconstructs the problem carried by a gRPC status.
+// Field violations are restored as RFC9457 validation errors.
+func FromStatus(st *status.Status) Problem {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	return fromStatus(st, HTTPStatus(st.Code()), st.Message())
+}
+
+func fromStatus(st *status.Status, httpStatus int, msg string, extra ...Option) Problem {
+	var opts []Option
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.ErrorInfo:
+			meta := v.GetMetadata()
+			if s, err := strconv.Atoi(meta["status"]); err == nil && s > 0 {
+				httpStatus = s
+			}
+			if meta["type"] != "" {
+				opts = append(opts, Type("%s", meta["type"]))
+			}
+			if meta["title"] != "" {
+				opts = append(opts, Title(meta["title"]))
+				if msg == meta["title"] && msg == st.Message() {
+					msg = ""
+				}
+			}
+			if meta["instance"] != "" {
+				opts = append(opts, Instance(meta["instance"]))
+			}
+			if ref := meta[ReferenceKey]; ref != "" {
+				opts = append(opts, Extension(ReferenceKey, ref))
+			}
+			if c := meta["code"]; c != "" {
+				opts = append(opts, Code(c))
+			} else if v.GetReason() != "" {
+				opts = append(opts, Code(v.GetReason()))
+			}
+		case *errdetails.BadRequest:
+			verrs := make([]ValidationError, 0, len(v.GetFieldViolations()))
+			for _, fv := range v.GetFieldViolations() {
+				verrs = append(verrs, ValidationError{Detail: fv.GetDescription(), Pointer: fieldToPointer(fv.GetField())})
+			}
+			opts = append(opts, ValidationErrors(nil, verrs...))
+		case *errdetails.RetryInfo:
+			if v.GetRetryDelay() != nil {
+				d := v.GetRetryDelay().AsDuration()
+				opts = append(opts, Extension(RetryAfterKey, int64(math.Ceil(d.Seconds()))), RetryAfter(d))
+			}
+		}
+	}
+	b := New(append(opts, extra...)...).internal()
+	return b.build(httpStatus, msg, b.f...)
+}
+
+func fieldToPointer(field string) string {
+	if strings.HasPrefix(field, "#") {
+		return field
+	}
+	return convertNamespaceToJsonPointer("." + field)
+}
+
+// retryDelay reads a retry-after value in seconds.
+func retryDelay(v interface{}) (time.Duration, bool) {
+	var seconds float64
+	switch value := v.(type) {
+	case int:
+		seconds = float64(value)
+	case int64:
+		seconds = float64(value)
+	case float64:
+		seconds = value
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return 0, false
+		}
+		seconds = f
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		seconds = f
+	default:
+		return 0, false
+	}
+	if seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
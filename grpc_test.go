@@ -0,0 +1,151 @@
+package problems
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	p := New(Instance("/users"), Code("INVALID_USER"), Type("https://example.com/probs/invalid-user"), Extension(RetryAfterKey, 30),
+		ValidationErrors(nil, ValidationError{Detail: "required", Pointer: "#/name"}),
+		InvalidParams(nil, InvalidParam{Name: "age", Reason: "must be a positive integer"})).BadRequest("invalid user")
+	st := ToStatus(p)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("expect = %v, actual = %v", codes.InvalidArgument, st.Code())
+	}
+	if st.Message() != "invalid user" {
+		t.Errorf("expect = invalid user, actual = %s", st.Message())
+	}
+	var info *errdetails.ErrorInfo
+	var br *errdetails.BadRequest
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.BadRequest:
+			br = v
+		case *errdetails.RetryInfo:
+			retry = v
+		}
+	}
+	if info == nil || info.Reason != "INVALID_USER" || info.Metadata["type"] != "https://example.com/probs/invalid-user" {
+		t.Errorf("expect = INVALID_USER, actual = %v", info)
+	}
+	if br == nil || len(br.FieldViolations) != 2 {
+		t.Errorf("expect = 2 field violations, actual = %v", br)
+	}
+	if retry == nil || retry.RetryDelay.AsDuration().Seconds() != 30 {
+		t.Errorf("expect = 30s, actual = %v", retry)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	p := New(Instance("/users"), Code("INVALID_USER"), Type("https://example.com/probs/invalid-user"),
+		ValidationErrors(nil, ValidationError{Detail: "required", Pointer: "#/name"})).BadRequest("invalid user")
+	actual := FromStatus(ToStatus(p))
+	br, ok := actual.(*BadRequest)
+	if !ok {
+		t.Fatalf("expect = BadRequest, actual = %v", actual)
+	}
+	if br.Status != http.StatusBadRequest || br.Type != "https://example.com/probs/invalid-user" || br.Code != "INVALID_USER" {
+		t.Errorf("expect = %s, actual = %s", p, br)
+	}
+	if br.Instance != "/users" || br.Detail != "invalid user" || br.Title != http.StatusText(http.StatusBadRequest) {
+		t.Errorf("expect = %s, actual = %s", p, br)
+	}
+	if len(br.Errors) != 1 || br.Errors[0].Pointer != "#/name" {
+		t.Errorf("expect = #/name, actual = %v", br.Errors)
+	}
+}
+
+func TestFromStatus_Foreign(t *testing.T) {
+	st, err := status.New(codes.NotFound, "user not found").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "user.items[0].id", Description: "unknown id"}},
+	})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	p := FromStatus(st)
+	br, ok := p.(*BadRequest)
+	if !ok {
+		t.Fatalf("expect = BadRequest, actual = %v", p)
+	}
+	if br.Status != http.StatusNotFound || br.Detail != "user not found" {
+		t.Errorf("expect = 404 user not found, actual = %d %s", br.Status, br.Detail)
+	}
+	if br.Errors[0].Pointer != "#/user/items/0/id" {
+		t.Errorf("expect = #/user/items/0/id, actual = %s", br.Errors[0].Pointer)
+	}
+	if FromStatus(status.New(codes.OK, "")) != nil {
+		t.Errorf("expect = nil")
+	}
+}
+
+func TestOf_Status(t *testing.T) {
+	tests := []struct {
+		code   codes.Code
+		status int
+	}{
+		{codes.Canceled, 499},
+		{codes.Unknown, http.StatusInternalServerError},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.FailedPrecondition, http.StatusBadRequest},
+		{codes.Aborted, http.StatusConflict},
+		{codes.OutOfRange, http.StatusBadRequest},
+		{codes.Unimplemented, http.StatusNotImplemented},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DataLoss, http.StatusInternalServerError},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		err := fmt.Errorf("call user service: %w", status.Error(test.code, "downstream message"))
+		p := Of(context.TODO(), "/users", err)
+		dp := p.(*DefaultProblem)
+		if dp.Status != test.status {
+			t.Errorf("%v: expect = %d, actual = %d", test.code, test.status, dp.Status)
+		}
+		if dp.Instance != "/users" {
+			t.Errorf("%v: expect = /users, actual = %s", test.code, dp.Instance)
+		}
+	}
+	p := Of(context.TODO(), "/users", status.Error(codes.NotFound, "user not found"))
+	if dp := p.(*DefaultProblem); dp.Detail != "user not found" {
+		t.Errorf("expect = user not found, actual = %s", dp.Detail)
+	}
+}
+
+func TestOf_StatusBadRequest(t *testing.T) {
+	st, _ := status.New(codes.InvalidArgument, "invalid user").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}},
+	})
+	p := Of(context.TODO(), "/users", st.Err())
+	br, ok := p.(*BadRequest)
+	if !ok {
+		t.Fatalf("expect = BadRequest, actual = %v", p)
+	}
+	if len(br.Errors) != 1 || br.Errors[0].Pointer != "#/name" || br.Errors[0].Detail != "required" {
+		t.Errorf("expect = #/name required, actual = %v", br.Errors)
+	}
+}
+
+func TestSetHTTPStatus(t *testing.T) {
+	SetHTTPStatus(codes.FailedPrecondition, http.StatusPreconditionFailed)
+	defer SetHTTPStatus(codes.FailedPrecondition, http.StatusBadRequest)
+	p := Of(context.TODO(), "/users", status.Error(codes.FailedPrecondition, "version mismatch"))
+	if p.ProblemStatus() != http.StatusPreconditionFailed {
+		t.Errorf("expect = %d, actual = %d", http.StatusPreconditionFailed, p.ProblemStatus())
+	}
+}
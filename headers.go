@@ -0,0 +1,164 @@
+package problems
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccha/http-constants/pkg/headers"
+)
+
+type retryAfter struct {
+	delay time.Duration
+	at    time.Time
+}
+
+// RetryAfter sets the Retry-After header written with the problem, as a delay or a date.
+func RetryAfter[T time.Duration | time.Time](v T) Option {
+	return func(p DefaultParams) Problem {
+		if dp := defaultProblemOf(p.(Problem)); dp != nil {
+			switch value := any(v).(type) {
+			case time.Duration:
+				dp.retryAfter = &retryAfter{delay: value}
+			case time.Time:
+				dp.retryAfter = &retryAfter{at: value}
+			}
+		}
+		return p.(Problem)
+	}
+}
+
+// RetryAfter returns the delay of the Retry-After header written with the problem.
+func (p *DefaultProblem) RetryAfter() (time.Duration, bool) {
+	if p == nil || p.retryAfter == nil {
+		return 0, false
+	}
+	return p.retryAfter.until(time.Now()), true
+}
+
+// until returns the delay from now.
+func (r *retryAfter) until(now time.Time) time.Duration {
+	if r.at.IsZero() {
+		return r.delay
+	}
+	if d := r.at.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header in delay-seconds or HTTP-date form.
+func parseRetryAfter(v string) (*retryAfter, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, false
+	}
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if seconds < 0 {
+			return nil, false
+		}
+		return &retryAfter{delay: time.Duration(seconds) * time.Second}, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return nil, false
+	}
+	return &retryAfter{at: t}, true
+}
+
+func (r *retryAfter) String() string {
+	if !r.at.IsZero() {
+		return r.at.UTC().Format(http.TimeFormat)
+	}
+	return strconv.FormatInt(int64(math.Ceil(r.delay.Seconds())), 10)
+}
+
+// Header sets a response header written with the problem, replacing previous values of the key.
+func Header(key string, values ...string) Option {
+	return func(p DefaultParams) Problem {
+		if dp := defaultProblemOf(p.(Problem)); dp != nil {
+			if dp.header == nil {
+				dp.header = http.Header{}
+			}
+			key = http.CanonicalHeaderKey(key)
+			dp.header[key] = append([]string(nil), values...)
+		}
+		return p.(Problem)
+	}
+}
+
+// Allow sets the Allow header, typically for 405 Method Not Allowed.
+func Allow(methods ...string) Option {
+	return Header(headers.Allow, strings.Join(methods, ", "))
+}
+
+// Challenge adds a WWW-Authenticate challenge, typically for 401 Unauthorized.
+func Challenge(scheme string, params map[string]string) Option {
+	return func(p DefaultParams) Problem {
+		if dp := defaultProblemOf(p.(Problem)); dp != nil {
+			if dp.header == nil {
+				dp.header = http.Header{}
+			}
+			dp.header.Add(headers.WWWAuthenticate, challenge(scheme, params))
+		}
+		return p.(Problem)
+	}
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func challenge(scheme string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"=\""+quoteEscaper.Replace(params[k])+"\"")
+	}
+	if len(pairs) == 0 {
+		return scheme
+	}
+	return scheme + " " + strings.Join(pairs, ", ")
+}
+
+// Header returns the response headers written with the problem, excluding Retry-After.
+func (p *DefaultProblem) Header() http.Header {
+	if p == nil {
+		return nil
+	}
+	return p.header.Clone()
+}
+
+// writeHeaders sets the headers carried by the problem.
+func writeHeaders(w http.ResponseWriter, v interface{}) {
+	p, ok := v.(Problem)
+	if !ok {
+		return
+	}
+	dp := defaultProblemOf(p)
+	if dp == nil {
+		return
+	}
+	for k, v := range dp.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+	if dp.retryAfter != nil {
+		w.Header().Set(headers.RetryAfter, dp.retryAfter.String())
+	}
+}
+
+// readHeaders restores the headers carried by the problem from a response.
+func readHeaders(p Problem, h http.Header) {
+	dp := defaultProblemOf(p)
+	if dp == nil {
+		return
+	}
+	if r, ok := parseRetryAfter(h.Get(headers.RetryAfter)); ok {
+		dp.retryAfter = r
+	}
+}
@@ -0,0 +1,140 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func TestRetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(RetryAfter(90*time.Second)).TooManyRequests("slow down").JSON(context.TODO(), w)
+	if v := w.Header().Get(headers.RetryAfter); v != "90" {
+		t.Errorf("expect = %v, actual = %v", "90", v)
+	}
+
+	at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	w = httptest.NewRecorder()
+	New(RetryAfter(at)).Unavailable("maintenance").XML(context.TODO(), w)
+	if v := w.Header().Get(headers.RetryAfter); v != "Tue, 01 Jan 2030 00:00:00 GMT" {
+		t.Errorf("expect = %v, actual = %v", "Tue, 01 Jan 2030 00:00:00 GMT", v)
+	}
+
+	w = httptest.NewRecorder()
+	New(RetryAfter(time.Second)).Unavailable("maintenance").(GraphQLRenderer).GraphQL(context.TODO(), w)
+	if v := w.Header().Get(headers.RetryAfter); v != "1" {
+		t.Errorf("expect = %v, actual = %v", "1", v)
+	}
+
+	w = httptest.NewRecorder()
+	New().Unavailable("maintenance").JSON(context.TODO(), w)
+	if v := w.Header().Get(headers.RetryAfter); v != "" {
+		t.Errorf("expect = empty, actual = %v", v)
+	}
+}
+
+func TestRetryAfter_CheckResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(RetryAfter(30*time.Second)).TooManyRequests("slow down").JSON(context.TODO(), w)
+	err := CheckResponse(w.Result())
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) {
+		t.Fatalf("expect = ProblemError, actual = %v", err)
+	}
+	if d, ok := defaultProblemOf(pe.Problem()).RetryAfter(); !ok || d != 30*time.Second {
+		t.Errorf("expect = %v, actual = %v", 30*time.Second, d)
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set(headers.RetryAfter, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	p := New().Unavailable("")
+	readHeaders(p, res.Header)
+	if d, ok := defaultProblemOf(p).RetryAfter(); !ok || d < 58*time.Minute || d > time.Hour {
+		t.Errorf("expect = %v, actual = %v", time.Hour, d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		v      string
+		expect time.Duration
+		ok     bool
+	}{
+		{v: "120", expect: 2 * time.Minute, ok: true},
+		{v: " 60 ", expect: time.Minute, ok: true},
+		{v: now.Add(time.Minute).Format(http.TimeFormat), expect: time.Minute, ok: true},
+		{v: now.Add(-time.Minute).Format(http.TimeFormat), expect: 0, ok: true},
+		{v: "-1", ok: false},
+		{v: "soon", ok: false},
+		{v: "", ok: false},
+	}
+	for _, tt := range tests {
+		var d time.Duration
+		r, ok := parseRetryAfter(tt.v)
+		if ok {
+			d = r.until(now)
+		}
+		if d != tt.expect || ok != tt.ok {
+			t.Errorf("expect = %v %v, actual = %v %v", tt.expect, tt.ok, d, ok)
+		}
+	}
+}
+
+func TestRetryAfter_Status(t *testing.T) {
+	st := ToStatus(New(RetryAfter(5 * time.Second)).TooManyRequests(""))
+	var info *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.RetryInfo); ok {
+			info = v
+		}
+	}
+	if info == nil || info.GetRetryDelay().AsDuration() != 5*time.Second {
+		t.Fatalf("expect = %v, actual = %v", 5*time.Second, info)
+	}
+	if d, ok := defaultProblemOf(FromStatus(st)).RetryAfter(); !ok || d != 5*time.Second {
+		t.Errorf("expect = %v, actual = %v", 5*time.Second, d)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(
+		Header("cache-control", "no-store"),
+		Header(headers.ContentType, "text/plain"),
+		Challenge("Bearer", map[string]string{"realm": "api", "error": "invalid_token", "error_description": `token "abc" expired`}),
+		Challenge("Basic", nil),
+	).Unauthorized("token expired").JSON(context.TODO(), w)
+	if v := w.Header().Get(headers.CacheControl); v != "no-store" {
+		t.Errorf("expect = %v, actual = %v", "no-store", v)
+	}
+	if v := w.Header().Get(headers.ContentType); v != "application/problem+json" {
+		t.Errorf("expect = %v, actual = %v", "application/problem+json", v)
+	}
+	expect := []string{`Bearer error="invalid_token", error_description="token \"abc\" expired", realm="api"`, "Basic"}
+	if v := w.Header().Values(headers.WWWAuthenticate); len(v) != 2 || v[0] != expect[0] || v[1] != expect[1] {
+		t.Errorf("expect = %v, actual = %v", expect, v)
+	}
+
+	w = httptest.NewRecorder()
+	p := New(Allow(http.MethodGet, http.MethodHead)).MethodNotAllowed("")
+	p.(GraphQLRenderer).GraphQL(context.TODO(), w)
+	if v := w.Header().Get(headers.Allow); v != "GET, HEAD" {
+		t.Errorf("expect = %v, actual = %v", "GET, HEAD", v)
+	}
+	if h := defaultProblemOf(p).Header(); h.Get(headers.Allow) != "GET, HEAD" {
+		t.Errorf("expect = %v, actual = %v", "GET, HEAD", h)
+	}
+
+	w = httptest.NewRecorder()
+	New(Header("Content-Language", "en"), Header("Content-Language", "ja")).NotFound("").XML(context.TODO(), w)
+	if v := w.Header().Values("Content-Language"); len(v) != 1 || v[0] != "ja" {
+		t.Errorf("expect = %v, actual = %v", "ja", v)
+	}
+}
@@ -0,0 +1,120 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCStatus allows status.FromError and status.Code to read the problem as a gRPC status.
+// A problem converted from a gRPC status returns that status.
+func (err *ProblemError) GRPCStatus() *status.Status {
+	if err.status != nil {
+		return err.status
+	}
+	return ToStatus(err.Problem())
+}
+
+// UnaryServerInterceptor converts errors returned by handlers into gRPC statuses carrying problem details.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return DefaultMapper.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor converts errors returned by stream handlers into gRPC statuses carrying problem details.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return DefaultMapper.StreamServerInterceptor()
+}
+
+// UnaryClientInterceptor converts gRPC statuses returned by the server into ProblemError.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return DefaultMapper.UnaryClientInterceptor()
+}
+
+// StreamClientInterceptor converts gRPC statuses returned by the server into ProblemError.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return DefaultMapper.StreamClientInterceptor()
+}
+
+// UnaryServerInterceptor is like the package-level UnaryServerInterceptor, converting errors with the mapper.
+func (m *Mapper) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err != nil {
+			err = m.toStatusError(ctx, info.FullMethod, err)
+		}
+		return res, err
+	}
+}
+
+// StreamServerInterceptor is like the package-level StreamServerInterceptor, converting errors with the mapper.
+func (m *Mapper) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return m.toStatusError(ss.Context(), info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+func (m *Mapper) toStatusError(ctx context.Context, method string, err error) error {
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+	}
+	return ToStatus(m.Of(ctx, method, err)).Err()
+}
+
+// UnaryClientInterceptor is like the package-level UnaryClientInterceptor, using the HTTP statuses of the mapper.
+func (m *Mapper) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return m.fromStatusError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is like the package-level StreamClientInterceptor, using the HTTP statuses of the mapper.
+func (m *Mapper) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, m.fromStatusError(err)
+		}
+		return &clientStream{ClientStream: cs, mapper: m}, nil
+	}
+}
+
+func (m *Mapper) fromStatusError(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if p := m.FromStatus(st); p != nil {
+		return &ProblemError{problem: p, status: st}
+	}
+	return err
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	mapper *Mapper
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return s.mapper.fromStatusError(s.ClientStream.SendMsg(m))
+}
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return s.mapper.fromStatusError(s.ClientStream.RecvMsg(m))
+}
+func (s *clientStream) CloseSend() error {
+	return s.mapper.fromStatusError(s.ClientStream.CloseSend())
+}
@@ -0,0 +1,181 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch req.Service {
+	case "problem":
+		return nil, New(Code("SERVICE_NOT_FOUND")).NotFound("service not found").Wrap()
+	case "status":
+		return nil, status.Error(codes.Aborted, "aborted")
+	case "error":
+		return nil, errors.New("connection refused")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, ss grpc_health_v1.Health_WatchServer) error {
+	return New().Unavailable("watch is not available").Wrap()
+}
+
+func newHealthClient(t *testing.T) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()), grpc.StreamInterceptor(StreamServerInterceptor()))
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	client := newHealthClient(t)
+	tests := []struct {
+		service string
+		status  int
+		code    codes.Code
+	}{
+		{"problem", http.StatusNotFound, codes.NotFound},
+		{"status", http.StatusConflict, codes.Aborted},
+		{"error", http.StatusInternalServerError, codes.Internal},
+	}
+	for _, test := range tests {
+		_, err := client.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: test.service})
+		pe := &ProblemError{}
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: expect = ProblemError, actual = %v", test.service, err)
+			continue
+		}
+		if actual := pe.Problem().ProblemStatus(); actual != test.status {
+			t.Errorf("%s: expect = %d, actual = %d", test.service, test.status, actual)
+		}
+		if actual := status.Code(err); actual != test.code {
+			t.Errorf("%s: expect = %v, actual = %v", test.service, test.code, actual)
+		}
+	}
+	_, err := client.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: "problem"})
+	pe := &ProblemError{}
+	if errors.As(err, &pe) {
+		dp := pe.Problem().(*DefaultProblem)
+		if dp.Code != "SERVICE_NOT_FOUND" || dp.Instance != grpc_health_v1.Health_Check_FullMethodName {
+			t.Errorf("expect = SERVICE_NOT_FOUND %s, actual = %s %s", grpc_health_v1.Health_Check_FullMethodName, dp.Code, dp.Instance)
+		}
+	}
+	if _, err = client.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	client := newHealthClient(t)
+	stream, err := client.Watch(context.TODO(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	_, err = stream.Recv()
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) {
+		t.Fatalf("expect = ProblemError, actual = %v", err)
+	}
+	if actual := pe.Problem().ProblemStatus(); actual != http.StatusServiceUnavailable {
+		t.Errorf("expect = %d, actual = %d", http.StatusServiceUnavailable, actual)
+	}
+}
+
+func TestUnaryClientInterceptor_Codes(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(code, "message")
+		}
+		err := interceptor(context.TODO(), "/svc/Method", nil, nil, nil, invoker)
+		if actual := status.Code(err); actual != code {
+			t.Errorf("expect = %v, actual = %v", code, actual)
+		}
+		if code == codes.OK {
+			continue
+		}
+		pe := &ProblemError{}
+		if !errors.As(err, &pe) {
+			t.Errorf("expect = ProblemError, actual = %v", err)
+		} else if actual := pe.Problem().ProblemStatus(); actual != HTTPStatus(code) {
+			t.Errorf("expect = %v, actual = %v", HTTPStatus(code), actual)
+		}
+	}
+}
+
+func TestMapper_SetHTTPStatus(t *testing.T) {
+	m := NewMapper().SetHTTPStatus(codes.FailedPrecondition, http.StatusPreconditionFailed)
+	err := status.Error(codes.FailedPrecondition, "version mismatch")
+	if p := m.Of(context.TODO(), "/users", err); p.ProblemStatus() != http.StatusPreconditionFailed {
+		t.Errorf("expect = %d, actual = %d", http.StatusPreconditionFailed, p.ProblemStatus())
+	}
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return err
+	}
+	pe := &ProblemError{}
+	if !errors.As(m.UnaryClientInterceptor()(context.TODO(), "/svc/Method", nil, nil, nil, invoker), &pe) ||
+		pe.Problem().ProblemStatus() != http.StatusPreconditionFailed {
+		t.Errorf("expect = %d, actual = %v", http.StatusPreconditionFailed, pe.Problem())
+	}
+	if p := Of(context.TODO(), "/users", err); p.ProblemStatus() != http.StatusBadRequest {
+		t.Errorf("expect = %d, actual = %d", http.StatusBadRequest, p.ProblemStatus())
+	}
+	if s := HTTPStatus(codes.FailedPrecondition); s != http.StatusBadRequest {
+		t.Errorf("expect = %d, actual = %d", http.StatusBadRequest, s)
+	}
+}
+
+func TestProblemError_GRPCStatus(t *testing.T) {
+	SetMode(Production)
+	defer SetMode(Development)
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+
+	err := WrapError(errors.New("secret"))
+	for i := 0; i < 3; i++ {
+		if code := status.Code(err); code != codes.Internal {
+			t.Errorf("expect = %v, actual = %v", codes.Internal, code)
+		}
+	}
+	ref := referenceOf(err.(*ProblemError).Problem())
+	if ref == "" || referenceOf(err.(*ProblemError).Problem()) != ref {
+		t.Errorf("expect = %v, actual = %v", ref, referenceOf(err.(*ProblemError).Problem()))
+	}
+	if len(l.entries) != 1 || l.entries[0].Fields[ReferenceKey] != ref {
+		t.Errorf("expect = %v, actual = %v", ref, l.entries)
+	}
+}
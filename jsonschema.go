@@ -0,0 +1,263 @@
+package problems
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+const (
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// Violations are the ValidationErrors of a problem document that does not conform to its schema.
+type Violations []ValidationError
+
+func (v Violations) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, ve := range v {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", ve.Pointer, ve.Detail))
+	}
+	return "problems: invalid problem document: " + strings.Join(msgs, "; ")
+}
+
+// JSONSchema returns a JSON Schema 2020-12 document validating problems,
+// whose $defs define the built-in problems and the types registered in r.
+// DefaultRegistry is used if r is nil.
+func JSONSchema(r *Registry) *Schema {
+	if r == nil {
+		r = DefaultRegistry
+	}
+	g := newSchemaGenerator("#/$defs/")
+	defs, _ := g.definitions(r)
+	return &Schema{Dialect: JSONSchemaDialect, Ref: g.ref(problemSchema).Ref, Defs: defs}
+}
+
+// Validate checks a problem+json or problem+xml document against RFC 9457 and the schema of its type in DefaultRegistry.
+// If status is given, the status member must match it.
+// The violations are returned as Violations.
+func Validate(doc []byte, status ...int) error {
+	return DefaultRegistry.Validate(doc, status...)
+}
+
+// ValidateResponse validates the body of a problem response against its status code, restoring the body.
+func ValidateResponse(res *http.Response) error {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get(headers.ContentType))
+	if mediaType != mimetypes.ProblemJson && mediaType != mimetypes.ProblemXml {
+		return Violations{{Pointer: "#", Detail: fmt.Sprintf("Content-Type '%s' is not a problem media type", mediaType)}}
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, DefaultMaxBodySize))
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return Validate(body, res.StatusCode)
+}
+
+// Validate checks a problem+json or problem+xml document against RFC 9457 and the schema of its type registered in r.
+// If status is given, the status member must match it.
+// The violations are returned as Violations.
+func (r *Registry) Validate(doc []byte, status ...int) error {
+	g := newSchemaGenerator("#/$defs/")
+	defs, types := g.definitions(r)
+	v := &schemaValidator{prefix: g.prefix, defs: defs}
+	value, ok := v.decode(doc, r)
+	if !ok {
+		return v.errs
+	}
+	s := defs[problemSchema]
+	if obj, ok := value.(map[string]interface{}); ok {
+		if uri, ok := obj["type"].(string); ok {
+			for _, t := range types {
+				if t.t.Type == uri {
+					s = defs[t.name]
+					break
+				}
+			}
+		}
+		if len(status) > 0 {
+			if n, ok := obj["status"].(json.Number); ok {
+				if f, err := n.Float64(); err == nil && f != float64(status[0]) {
+					v.add("#/status", "does not match the response status %d", status[0])
+				}
+			}
+		}
+	}
+	v.validate(s, value, "#")
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	prefix string
+	defs   map[string]*Schema
+	errs   Violations
+}
+
+func (v *schemaValidator) add(pointer, format string, args ...interface{}) {
+	ve := ValidationError{Detail: fmt.Sprintf(format, args...), Pointer: pointer}
+	for _, e := range v.errs {
+		if e == ve {
+			return
+		}
+	}
+	v.errs = append(v.errs, ve)
+}
+
+// decode decodes a JSON document, or converts an XML document guided by the Go type registered for its type URI.
+func (v *schemaValidator) decode(doc []byte, r *Registry) (interface{}, bool) {
+	if isXML(doc) {
+		root, err := parseXML(doc)
+		if err != nil {
+			v.add("#", "is not a valid XML document: %v", err)
+			return nil, false
+		}
+		if err = root.checkProblem(); err != nil {
+			v.add("#", "root element must be problem in namespace %s", XmlNamespace)
+			return nil, false
+		}
+		var p Problem = &DefaultProblem{}
+		if n := root.child("type"); n != nil {
+			if rp := r.Create(n.value()); rp != nil {
+				p = rp
+			}
+		}
+		bin, err := json.Marshal(root.toJSON(reflect.TypeOf(p)))
+		if err != nil {
+			v.add("#", "is not a valid problem: %v", err)
+			return nil, false
+		}
+		doc = bin
+	}
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		v.add("#", "is not a valid JSON document: %v", err)
+		return nil, false
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		v.add("#", "must be an object")
+		return nil, false
+	}
+	return value, true
+}
+
+func (v *schemaValidator) validate(s *Schema, value interface{}, pointer string) {
+	if s.Ref != "" {
+		if def, ok := v.defs[strings.TrimPrefix(s.Ref, v.prefix)]; ok {
+			v.validate(def, value, pointer)
+		}
+	}
+	for _, sub := range s.AllOf {
+		v.validate(sub, value, pointer)
+	}
+	if s.Type != "" && !isType(s.Type, value) {
+		article := "a"
+		if strings.ContainsRune("aeiou", rune(s.Type[0])) {
+			article = "an"
+		}
+		v.add(pointer, "must be %s %s", article, s.Type)
+		return
+	}
+	if s.Const != nil && fmt.Sprint(value) != fmt.Sprint(s.Const) {
+		v.add(pointer, "must be %v", s.Const)
+	}
+	switch value := value.(type) {
+	case string:
+		if s.Format == "uri-reference" && !isURIReference(value) {
+			v.add(pointer, "must be a URI reference")
+		}
+	case json.Number:
+		f, _ := value.Float64()
+		if s.Minimum != nil && f < float64(*s.Minimum) {
+			v.add(pointer, "must be at least %d", *s.Minimum)
+		}
+		if s.Maximum != nil && f > float64(*s.Maximum) {
+			v.add(pointer, "must be at most %d", *s.Maximum)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range value {
+				v.validate(s.Items, item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				v.add(pointer+"/"+escapePointer(name), "is required")
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				v.validate(p, value[k], pointer+"/"+escapePointer(k))
+			} else if s.AdditionalProperties != nil {
+				v.validate(s.AdditionalProperties, value[k], pointer+"/"+escapePointer(k))
+			}
+		}
+	}
+}
+
+func isType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// isURIReference reports whether s is a URI reference (RFC 3986) without spaces or control characters.
+func isURIReference(s string) bool {
+	if strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return false
+	}
+	_, err := url.Parse(s)
+	return err == nil
+}
+
+// escapePointer escapes a member name as a JSON Pointer (RFC 6901) reference token.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
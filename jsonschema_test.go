@@ -0,0 +1,138 @@
+package problems
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(outOfCreditRegistry())
+	if s.Dialect != JSONSchemaDialect || s.Ref != "#/$defs/Problem" {
+		t.Errorf("expect = %v, actual = %v", JSONSchemaDialect, s)
+	}
+	for _, name := range []string{"Problem", "BadRequest", "InvalidParam", "ValidationError", "OutOfCredit"} {
+		if _, ok := s.Defs[name]; !ok {
+			t.Errorf("expect = %v, actual = %v", name, s.Defs)
+		}
+	}
+	if ref := s.Defs["BadRequest"].AllOf[0].Ref; ref != "#/$defs/Problem" {
+		t.Errorf("expect = %v, actual = %v", "#/$defs/Problem", ref)
+	}
+	bin, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bin), `"$schema":"https://json-schema.org/draft/2020-12/schema"`) {
+		t.Errorf("expect = %v, actual = %v", JSONSchemaDialect, string(bin))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		doc    string
+		status []int
+		expect []ValidationError
+	}{
+		{doc: `{"type":"https://example.com/probs/a","title":"A","status":404,"detail":"d","instance":"/a/1","ext":{"x":1}}`, status: []int{404}},
+		{doc: `{}`},
+		{doc: `{"status":404.0}`, status: []int{404}},
+		{doc: `{"type":"not a uri","title":1,"status":"404","instance":"/a b"}`, expect: []ValidationError{
+			{Pointer: "#/instance", Detail: "must be a URI reference"},
+			{Pointer: "#/status", Detail: "must be an integer"},
+			{Pointer: "#/title", Detail: "must be a string"},
+			{Pointer: "#/type", Detail: "must be a URI reference"},
+		}},
+		{doc: `{"status":404}`, status: []int{500}, expect: []ValidationError{
+			{Pointer: "#/status", Detail: "does not match the response status 500"},
+		}},
+		{doc: `{"status":99.5}`, expect: []ValidationError{
+			{Pointer: "#/status", Detail: "must be an integer"},
+		}},
+		{doc: `{"status":600}`, expect: []ValidationError{
+			{Pointer: "#/status", Detail: "must be at most 599"},
+		}},
+		{doc: `{"type":"https://example.com/probs/out-of-credit","accounts":"a"}`, expect: []ValidationError{
+			{Pointer: "#/balance", Detail: "is required"},
+			{Pointer: "#/accounts", Detail: "must be an array"},
+		}},
+		{doc: `[]`, expect: []ValidationError{{Pointer: "#", Detail: "must be an object"}}},
+	}
+	r := outOfCreditRegistry()
+	for _, tt := range tests {
+		err := r.Validate([]byte(tt.doc), tt.status...)
+		if tt.expect == nil {
+			if err != nil {
+				t.Errorf("expect = %v, actual = %v", nil, err)
+			}
+			continue
+		}
+		var v Violations
+		if !errors.As(err, &v) {
+			t.Errorf("expect = %v, actual = %v", "Violations", err)
+			continue
+		}
+		if !reflect.DeepEqual([]ValidationError(v), tt.expect) {
+			t.Errorf("expect = %v, actual = %v", tt.expect, v)
+		}
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	r := NewRegistry()
+	_ = r.Register("https://example.com/probs/invalid", func() Problem {
+		return &BadRequest{DefaultProblem: &DefaultProblem{}}
+	})
+	doc := []byte(`{"type":"https://example.com/probs/invalid","invalid-params":[{"name":"age"}],"errors":[{"detail":"d","pointer":1}]}`)
+	expect := Violations{
+		{Pointer: "#/errors/0/pointer", Detail: "must be a string"},
+		{Pointer: "#/invalid-params/0/reason", Detail: "is required"},
+	}
+	var v Violations
+	if err := r.Validate(doc); !errors.As(err, &v) || !reflect.DeepEqual(v, expect) {
+		t.Errorf("expect = %v, actual = %v", expect, err)
+	}
+	// without the registration, extension members are not validated
+	if err := NewRegistry().Validate(doc); err != nil {
+		t.Errorf("expect = %v, actual = %v", nil, err)
+	}
+}
+
+func TestValidate_XML(t *testing.T) {
+	p := New(Type(outOfCreditType)).Forbidden("balance is 30").(*DefaultProblem)
+	w := httptest.NewRecorder()
+	WriteXml(context.Background(), w, p.Status, &OutOfCredit{DefaultProblem: p, Balance: 30, Accounts: []string{"a"}})
+	r := outOfCreditRegistry()
+	if err := r.Validate(w.Body.Bytes(), http.StatusForbidden); err != nil {
+		t.Errorf("expect = %v, actual = %v", nil, err)
+	}
+	doc := `<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type><status>forbidden</status><balance>30</balance></problem>`
+	var v Violations
+	if err := r.Validate([]byte(doc)); !errors.As(err, &v) || len(v) != 1 || v[0].Pointer != "#/status" {
+		t.Errorf("expect = %v, actual = %v", "#/status", err)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	res := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Type": []string{"application/problem+json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"title":"Not Found","status":404}`)),
+	}
+	if err := ValidateResponse(res); err != nil {
+		t.Errorf("expect = %v, actual = %v", nil, err)
+	}
+	if body, _ := io.ReadAll(res.Body); string(body) != `{"title":"Not Found","status":404}` {
+		t.Errorf("expect = %v, actual = %v", "restored body", string(body))
+	}
+	res.Header.Set("Content-Type", "application/json")
+	if err := ValidateResponse(res); err == nil {
+		t.Errorf("expect = %v, actual = %v", "error", err)
+	}
+}
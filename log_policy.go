@@ -0,0 +1,227 @@
+package problems
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	SuppressedKey = "suppressed"
+	// MaxDedupKeys bounds the distinct entries tracked for deduplication; the least recently logged are evicted.
+	MaxDedupKeys = 1024
+)
+
+// LogRule decides how Of logs problems matching a status range or a type.
+type LogRule struct {
+	// MinStatus and MaxStatus are inclusive bounds; zero leaves the bound open.
+	MinStatus int
+	MaxStatus int
+	// Type matches the problem type when not empty.
+	Type  string
+	Level Level
+	Stack bool
+}
+
+func (r LogRule) match(p Problem) bool {
+	status := p.ProblemStatus()
+	if r.MinStatus > 0 && status < r.MinStatus {
+		return false
+	}
+	if r.MaxStatus > 0 && status > r.MaxStatus {
+		return false
+	}
+	if r.Type != "" {
+		dp := defaultProblemOf(p)
+		if dp == nil || dp.Type != r.Type {
+			return false
+		}
+	}
+	return true
+}
+
+type logWindow struct {
+	key        string
+	level      Level
+	message    string
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// flush returns the entry reporting the entries suppressed in the window.
+func (w *logWindow) flush() Entry {
+	return Entry{Level: w.level, Message: w.message, Fields: map[string]interface{}{SuppressedKey: w.suppressed}}
+}
+
+// LogPolicy maps problems to log levels and deduplicates identical errors within a window.
+// References of suppressed entries are not logged.
+type LogPolicy struct {
+	mu     sync.Mutex
+	rules  []LogRule
+	window time.Duration
+	burst  int
+	// seen indexes the windows of lru, which is ordered from the most recently logged
+	seen    map[string]*list.Element
+	lru     *list.List
+	swept   time.Time
+	flushed []Entry
+	now     func() time.Time
+}
+
+func NewLogPolicy() *LogPolicy {
+	return &LogPolicy{now: time.Now}
+}
+
+// DefaultLogPolicy is the policy used by Of.
+// Without rules, 4xx and 503 are logged at warn and other 5xx at error, all with stack.
+var DefaultLogPolicy = NewLogPolicy()
+
+// Add appends rules; the first matching rule wins.
+func (lp *LogPolicy) Add(rules ...LogRule) *LogPolicy {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	lp.rules = append(lp.rules, rules...)
+	return lp
+}
+
+// Status logs problems whose status is between min and max at the level.
+func (lp *LogPolicy) Status(min, max int, level Level, stack bool) *LogPolicy {
+	return lp.Add(LogRule{MinStatus: min, MaxStatus: max, Level: level, Stack: stack})
+}
+
+// Type logs problems of the type at the level.
+func (lp *LogPolicy) Type(uri string, level Level, stack bool) *LogPolicy {
+	return lp.Add(LogRule{Type: uri, Level: level, Stack: stack})
+}
+
+// Dedup logs at most burst identical entries per window and reports the number suppressed
+// with the next entry logged. A zero window disables deduplication.
+func (lp *LogPolicy) Dedup(window time.Duration, burst int) *LogPolicy {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+	lp.window, lp.burst = window, burst
+	lp.seen = map[string]*list.Element{}
+	lp.lru = list.New()
+	lp.flushed = nil
+	return lp
+}
+
+func (lp *LogPolicy) rule(p Problem) LogRule {
+	for _, r := range lp.rules {
+		if r.match(p) {
+			return r
+		}
+	}
+	if status := p.ProblemStatus(); status < http.StatusInternalServerError || status == http.StatusServiceUnavailable {
+		return LogRule{Level: LevelWarn, Stack: true}
+	}
+	return LogRule{Level: LevelError, Stack: true}
+}
+
+func (lp *LogPolicy) log(ctx context.Context, p Problem, err error, ref string) {
+	e, ok := lp.entry(p, err, ref)
+	for _, f := range lp.drain() {
+		currentLogger().Log(ctx, f)
+	}
+	if ok {
+		currentLogger().Log(ctx, e)
+	}
+}
+
+// drain returns the suppressed counts of the windows removed by dedup.
+func (lp *LogPolicy) drain() []Entry {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	flushed := lp.flushed
+	lp.flushed = nil
+	return flushed
+}
+
+func (lp *LogPolicy) entry(p Problem, err error, ref string) (Entry, bool) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	r := lp.rule(p)
+	if r.Level >= LevelOff {
+		return Entry{}, false
+	}
+	e := Entry{Level: r.Level, Message: fmt.Sprintf("%+v", err), Stack: r.Stack, Fields: map[string]interface{}{}}
+	if r.Level >= LevelError {
+		e.Err = err
+	}
+	if ref != "" {
+		e.Fields[ReferenceKey] = ref
+	}
+	if lp.window > 0 {
+		suppressed, ok := lp.dedup(fmt.Sprintf("%d|%d|%s", r.Level, p.ProblemStatus(), err), e)
+		if !ok {
+			return Entry{}, false
+		}
+		if suppressed > 0 {
+			e.Fields[SuppressedKey] = suppressed
+		}
+	}
+	if len(e.Fields) == 0 {
+		e.Fields = nil
+	}
+	return e, true
+}
+
+// dedup reports whether the entry of the key may be logged, with the number suppressed in the previous window.
+// At most MaxDedupKeys windows are kept; expired and evicted windows with suppressed entries are flushed.
+func (lp *LogPolicy) dedup(key string, e Entry) (int, bool) {
+	now := time.Now()
+	if lp.now != nil {
+		now = lp.now()
+	}
+	suppressed, ok := 0, true
+	if el, found := lp.seen[key]; found && now.Sub(el.Value.(*logWindow).start) < lp.window {
+		w := el.Value.(*logWindow)
+		lp.lru.MoveToFront(el)
+		if w.count < lp.burst {
+			w.count++
+		} else {
+			w.suppressed++
+			ok = false
+		}
+	} else {
+		if found {
+			suppressed = el.Value.(*logWindow).suppressed
+			lp.remove(el)
+		}
+		lp.seen[key] = lp.lru.PushFront(&logWindow{key: key, level: e.Level, message: e.Message, start: now, count: 1})
+		for lp.lru.Len() > MaxDedupKeys {
+			lp.evict(lp.lru.Back())
+		}
+	}
+	if now.Sub(lp.swept) >= lp.window {
+		lp.swept = now
+		for el := lp.lru.Back(); el != nil; {
+			prev := el.Prev()
+			if now.Sub(el.Value.(*logWindow).start) >= lp.window {
+				lp.evict(el)
+			}
+			el = prev
+		}
+	}
+	return suppressed, ok
+}
+
+// evict removes the window, flushing its suppressed entries.
+func (lp *LogPolicy) evict(el *list.Element) {
+	if w := el.Value.(*logWindow); w.suppressed > 0 {
+		lp.flushed = append(lp.flushed, w.flush())
+	}
+	lp.remove(el)
+}
+
+func (lp *LogPolicy) remove(el *list.Element) {
+	delete(lp.seen, el.Value.(*logWindow).key)
+	lp.lru.Remove(el)
+}
@@ -0,0 +1,135 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLogPolicy(t *testing.T) {
+	lp := NewLogPolicy().
+		Type(outOfCreditType, LevelInfo, false).
+		Status(400, 499, LevelDebug, false).
+		Status(503, 503, LevelOff, false).
+		Status(500, 599, LevelError, true)
+
+	tests := []struct {
+		p     Problem
+		level Level
+		stack bool
+		ok    bool
+	}{
+		{p: New().NotFound(""), level: LevelDebug, ok: true},
+		{p: New(Type("%s", outOfCreditType)).Forbidden(""), level: LevelInfo, ok: true},
+		{p: New().Unavailable(""), ok: false},
+		{p: New().InternalServerError(""), level: LevelError, stack: true, ok: true},
+	}
+	err := errors.New("failure")
+	for _, tt := range tests {
+		e, ok := lp.entry(tt.p, err, "")
+		if ok != tt.ok || e.Level != tt.level || e.Stack != tt.stack {
+			t.Errorf("expect = %v %v %v, actual = %v %v %v", tt.level, tt.stack, tt.ok, e.Level, e.Stack, ok)
+		}
+	}
+	if e, _ := lp.entry(New().InternalServerError(""), err, ""); e.Err != err {
+		t.Errorf("expect = %v, actual = %v", err, e.Err)
+	}
+	if e, _ := lp.entry(New().NotFound(""), err, "abc"); e.Err != nil || e.Fields[ReferenceKey] != "abc" {
+		t.Errorf("expect = %v, actual = %v", "abc", e.Fields)
+	}
+}
+
+func TestLogPolicy_Default(t *testing.T) {
+	lp := NewLogPolicy()
+	if e, _ := lp.entry(New().NotFound(""), errors.New("failure"), ""); e.Level != LevelWarn || !e.Stack {
+		t.Errorf("expect = %v, actual = %v", LevelWarn, e.Level)
+	}
+	if e, _ := lp.entry(New().Unavailable(""), errors.New("failure"), ""); e.Level != LevelWarn {
+		t.Errorf("expect = %v, actual = %v", LevelWarn, e.Level)
+	}
+	if e, _ := lp.entry(New().BadGateway(""), errors.New("failure"), ""); e.Level != LevelError {
+		t.Errorf("expect = %v, actual = %v", LevelError, e.Level)
+	}
+}
+
+func TestLogPolicy_Dedup(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lp := NewLogPolicy().Dedup(time.Minute, 2)
+	lp.now = func() time.Time { return now }
+
+	p := New().InternalServerError("")
+	logged := 0
+	for i := 0; i < 5; i++ {
+		if _, ok := lp.entry(p, errors.New("connection refused"), ""); ok {
+			logged++
+		}
+	}
+	if logged != 2 {
+		t.Errorf("expect = %v, actual = %v", 2, logged)
+	}
+	if _, ok := lp.entry(p, errors.New("timeout"), ""); !ok {
+		t.Errorf("expect = %v, actual = %v", true, ok)
+	}
+
+	now = now.Add(time.Minute)
+	e, ok := lp.entry(p, errors.New("connection refused"), "")
+	if !ok || e.Fields[SuppressedKey] != 3 {
+		t.Errorf("expect = %v, actual = %v", 3, e.Fields[SuppressedKey])
+	}
+}
+
+func TestLogPolicy_ZeroValue(t *testing.T) {
+	lp := (&LogPolicy{}).Dedup(time.Minute, 1)
+	p := New().InternalServerError("")
+	if _, ok := lp.entry(p, errors.New("failure"), ""); !ok {
+		t.Errorf("expect = %v, actual = %v", true, ok)
+	}
+	if _, ok := lp.entry(p, errors.New("failure"), ""); ok {
+		t.Errorf("expect = %v, actual = %v", false, ok)
+	}
+}
+
+func TestDefaultLogPolicy(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+	DefaultLogPolicy = NewLogPolicy().Status(http.StatusInternalServerError, 0, LevelOff, false)
+	defer func() { DefaultLogPolicy = NewLogPolicy() }()
+
+	Of(context.TODO(), "/users", errors.New("failure"))
+	if len(l.entries) != 0 {
+		t.Errorf("expect = %v, actual = %v", 0, len(l.entries))
+	}
+}
+
+func TestLogPolicy_DedupFlush(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lp := NewLogPolicy().Dedup(time.Minute, 1)
+	lp.now = func() time.Time { return now }
+
+	p := New().InternalServerError("")
+	for i := 0; i < 3; i++ {
+		lp.log(context.TODO(), p, errors.New("connection refused"), "")
+	}
+	now = now.Add(time.Minute)
+	lp.log(context.TODO(), p, errors.New("timeout"), "")
+	if len(l.entries) != 3 || l.entries[1].Fields[SuppressedKey] != 2 {
+		t.Errorf("expect = %v, actual = %v", 2, l.entries)
+	}
+	if lp.lru.Len() != 1 || len(lp.seen) != 1 {
+		t.Errorf("expect = %v, actual = %v", 1, len(lp.seen))
+	}
+
+	for i := 0; i < MaxDedupKeys+10; i++ {
+		lp.entry(p, fmt.Errorf("failure %d", i), "")
+	}
+	if lp.lru.Len() != MaxDedupKeys || len(lp.seen) != MaxDedupKeys {
+		t.Errorf("expect = %v, actual = %v", MaxDedupKeys, len(lp.seen))
+	}
+}
@@ -0,0 +1,231 @@
+package problems
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccha/logging/log"
+	"github.com/goccha/logging/tracing"
+	"github.com/rs/zerolog"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelOff disables logging in a LogPolicy.
+	LevelOff
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelOff:
+		return "OFF"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// Entry is a log event emitted by the package.
+type Entry struct {
+	Level   Level
+	Message string
+	Err     error
+	// Stack requests the stack trace of Err.
+	Stack  bool
+	Fields map[string]interface{}
+}
+
+// Logger receives the log events of the package.
+type Logger interface {
+	Log(ctx context.Context, e Entry)
+}
+
+// LoggerFunc adapts a function to Logger.
+type LoggerFunc func(ctx context.Context, e Entry)
+
+func (f LoggerFunc) Log(ctx context.Context, e Entry) {
+	f(ctx, e)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(context.Context, Entry) {}
+
+// NopLogger discards every entry.
+var NopLogger Logger = nopLogger{}
+
+type zerologLogger struct {
+	// out, if set, replaces the writers of goccha/logging
+	out io.Writer
+}
+
+// ZerologLogger writes entries with github.com/goccha/logging/log.
+var ZerologLogger Logger = zerologLogger{}
+
+// NewZerologLogger writes entries like ZerologLogger, but to w instead of the writers of goccha/logging.
+func NewZerologLogger(w io.Writer) Logger {
+	return zerologLogger{out: w}
+}
+
+func (l zerologLogger) Log(ctx context.Context, e Entry) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	event := l.event(ctx, e.Level, callerSkip())
+	event = log.EmbedObject(ctx, event)
+	if e.Stack {
+		event = event.Stack()
+	}
+	if e.Err != nil {
+		event = event.Err(e.Err)
+	}
+	for _, k := range sortedKeys(e.Fields) {
+		event = event.Interface(k, e.Fields[k])
+	}
+	event.Msg(e.Message)
+}
+
+// event returns the event of the level; warnings and errors report the caller skip frames above Log.
+func (l zerologLogger) event(ctx context.Context, level Level, skip int) *zerolog.Event {
+	if l.out == nil {
+		switch level {
+		case LevelDebug:
+			return log.Debug(ctx)
+		case LevelInfo:
+			return log.Info(ctx)
+		case LevelError:
+			return log.Error(ctx, skip)
+		}
+		return log.Warn(ctx, skip)
+	}
+	logger := zerolog.New(l.out).With().Timestamp().Logger()
+	switch level {
+	case LevelDebug:
+		return tracing.WithTrace(ctx, logger.Debug()).Str("severity", "DEBUG")
+	case LevelInfo:
+		return tracing.WithTrace(ctx, logger.Info()).Str("severity", "INFO")
+	}
+	logger = logger.With().CallerWithSkipFrameCount(zerolog.CallerSkipFrameCount + skip).Logger()
+	if level == LevelError {
+		return tracing.WithTrace(ctx, logger.Error()).Str("severity", "ERROR")
+	}
+	return tracing.WithTrace(ctx, logger.Warn()).Str("severity", "WARNING")
+}
+
+var packagePath = reflect.TypeOf(nopLogger{}).PkgPath()
+
+// callerSkip returns the number of frames from the caller of Log to the first caller outside this package,
+// so that the caller field of an entry is its call site.
+func callerSkip() int {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for skip := 1; ; skip++ {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, packagePath+".") {
+			return skip
+		}
+		if !more {
+			return 0
+		}
+	}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// SlogLogger writes entries to the slog logger, or to slog.Default if it is nil.
+func SlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Log(ctx context.Context, e Entry) {
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := slog.LevelWarn
+	switch e.Level {
+	case LevelDebug:
+		level = slog.LevelDebug
+	case LevelInfo:
+		level = slog.LevelInfo
+	case LevelError:
+		level = slog.LevelError
+	}
+	attrs := make([]slog.Attr, 0, len(e.Fields)+2)
+	if e.Err != nil {
+		attrs = append(attrs, slog.Any("error", e.Err))
+		if e.Stack {
+			attrs = append(attrs, slog.String("stack", fmt.Sprintf("%+v", e.Err)))
+		}
+	}
+	for _, k := range sortedKeys(e.Fields) {
+		attrs = append(attrs, slog.Any(k, e.Fields[k]))
+	}
+	logger.LogAttrs(ctx, level, e.Message, attrs...)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	loggerMu      sync.RWMutex
+	defaultLogger = ZerologLogger
+)
+
+// SetLogger sets the logger used by the package and by builders without their own logger.
+// A nil logger discards every entry.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NopLogger
+	}
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	defaultLogger = l
+}
+
+func currentLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return defaultLogger
+}
+
+// loggerOf returns the logger of the builder that built v, or the global logger.
+func loggerOf(v interface{}) Logger {
+	if p, ok := v.(Problem); ok {
+		if dp := defaultProblemOf(p); dp != nil && dp.logger != nil {
+			return dp.logger
+		}
+	}
+	return currentLogger()
+}
@@ -0,0 +1,35 @@
+package problems_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/goccha/problems"
+)
+
+// TestZerologLogger_Caller runs outside the package, so that the caller is found by skipping the frames of the package.
+func TestZerologLogger_Caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	problems.SetLogger(problems.NewZerologLogger(buf))
+	defer problems.SetLogger(problems.ZerologLogger)
+	_, file, line, _ := runtime.Caller(0)
+	problems.Of(context.TODO(), "/users", errors.New("failure"))
+	var entry struct {
+		Caller   string `json:"caller"`
+		Severity string `json:"severity"`
+	}
+	if err := json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &entry); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	if expect := fmt.Sprintf("%s:%d", file, line+1); entry.Caller != expect {
+		t.Errorf("expect = %v, actual = %v", expect, entry.Caller)
+	}
+	if entry.Severity != "ERROR" {
+		t.Errorf("expect = %v, actual = %v", "ERROR", entry.Severity)
+	}
+}
@@ -0,0 +1,79 @@
+package problems
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type captureLogger struct {
+	entries []Entry
+}
+
+func (l *captureLogger) Log(ctx context.Context, e Entry) {
+	l.entries = append(l.entries, e)
+}
+
+func TestSetLogger(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+
+	err := errors.New("failure")
+	Of(context.TODO(), "/users", err)
+	if len(l.entries) != 1 {
+		t.Fatalf("expect = %v, actual = %v", 1, len(l.entries))
+	}
+	if e := l.entries[0]; e.Level != LevelError || e.Err != err || !e.Stack {
+		t.Errorf("expect = %v, actual = %v", LevelError, e.Level)
+	}
+
+	Of(context.TODO(), "/users", New().Unavailable("maintenance").Wrap())
+	Of(context.TODO(), "/users", WrapError(err))
+	if _, err := Bind(context.TODO(), http.StatusBadRequest, []byte("{")); err == nil {
+		t.Errorf("expect = error, actual = %v", err)
+	}
+	if len(l.entries) != 2 || l.entries[1].Level != LevelError || l.entries[1].Message != "{" {
+		t.Errorf("expect = %v, actual = %v", 2, l.entries)
+	}
+}
+
+func TestBuilder_Logger(t *testing.T) {
+	l := &captureLogger{}
+	p := New().Logger(l).Mode(Production).InternalServerError("secret")
+	if len(l.entries) != 1 || l.entries[0].Message != "secret" || l.entries[0].Fields[ReferenceKey] != referenceOf(p) {
+		t.Errorf("expect = %v, actual = %v", "secret", l.entries)
+	}
+	if loggerOf(p) != Logger(l) {
+		t.Errorf("expect = %v, actual = %v", l, loggerOf(p))
+	}
+	if loggerOf(New().NotFound("")) != currentLogger() {
+		t.Errorf("expect = %v, actual = %v", currentLogger(), loggerOf(New().NotFound("")))
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := SlogLogger(slog.New(slog.NewTextHandler(buf, nil)))
+	l.Log(context.TODO(), Entry{Level: LevelError, Message: "failure", Err: errors.New("boom"), Fields: map[string]interface{}{ReferenceKey: "abc"}})
+	if s := buf.String(); !strings.Contains(s, "level=ERROR") || !strings.Contains(s, "error=boom") || !strings.Contains(s, "reference=abc") {
+		t.Errorf("expect = %v, actual = %v", "level=ERROR error=boom reference=abc", s)
+	}
+	buf.Reset()
+	l.Log(context.TODO(), Entry{Level: LevelDebug, Message: "ignored"})
+	if buf.Len() != 0 {
+		t.Errorf("expect = empty, actual = %v", buf.String())
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	SetLogger(nil)
+	defer SetLogger(ZerologLogger)
+	if currentLogger() != NopLogger {
+		t.Errorf("expect = %v, actual = %v", NopLogger, currentLogger())
+	}
+}
@@ -0,0 +1,124 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule converts an error into a problem, or returns nil when it does not apply.
+type Rule func(ctx context.Context, path string, err error) Problem
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Mapper holds the rules consulted by Of before its default conversion.
+type Mapper struct {
+	mu    sync.RWMutex
+	rules []Rule
+	// statuses overrides the HTTP statuses of gRPC codes
+	statuses map[codes.Code]int
+}
+
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// DefaultMapper is the mapper used by Of.
+var DefaultMapper = NewMapper()
+
+// Add appends rules, which are evaluated in the order they were added.
+func (m *Mapper) Add(rules ...Rule) *Mapper {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rules...)
+	return m
+}
+
+// Is maps errors matching target with errors.Is to a problem with the status.
+func (m *Mapper) Is(target error, status int, opts ...Option) *Mapper {
+	return m.Add(func(ctx context.Context, path string, err error) Problem {
+		if errors.Is(err, target) {
+			return mapped(path, err, status, opts...)
+		}
+		return nil
+	})
+}
+
+// As maps errors matching target with errors.As to a problem with the status.
+// As with errors.As, target must be a non-nil pointer to an error type or an interface, e.g. new(*MyError).
+func (m *Mapper) As(target interface{}, status int, opts ...Option) *Mapper {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic("problems: target must be a non-nil pointer")
+	}
+	if e := t.Elem(); e.Kind() != reflect.Interface && !e.Implements(errorType) {
+		panic("problems: *target must be interface or implement error")
+	}
+	return m.Add(func(ctx context.Context, path string, err error) Problem {
+		if errors.As(err, reflect.New(t.Elem()).Interface()) {
+			return mapped(path, err, status, opts...)
+		}
+		return nil
+	})
+}
+
+// mapped builds the problem of a matching rule; the error text is not exposed unless an option sets the detail.
+func mapped(path string, err error, status int, opts ...Option) Problem {
+	b := New(append([]Option{Instance(path), Wrap(err)}, opts...)...).internal()
+	return b.build(status, "", b.f...)
+}
+
+// SetHTTPStatus overrides the HTTP status of the gRPC code for this mapper only.
+func (m *Mapper) SetHTTPStatus(code codes.Code, status int) *Mapper {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.statuses == nil {
+		m.statuses = make(map[codes.Code]int)
+	}
+	m.statuses[code] = status
+	return m
+}
+
+// HTTPStatus returns the HTTP status of the gRPC code, falling back to the package-level HTTPStatus.
+func (m *Mapper) HTTPStatus(code codes.Code) int {
+	m.mu.RLock()
+	s, ok := m.statuses[code]
+	m.mu.RUnlock()
+	if ok {
+		return s
+	}
+	return HTTPStatus(code)
+}
+
+// FromStatus reconstructs the problem carried by a gRPC status like the package-level FromStatus,
+// using the HTTP statuses of the mapper.
+func (m *Mapper) FromStatus(st *status.Status) Problem {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	return fromStatus(st, m.HTTPStatus(st.Code()), st.Message())
+}
+
+// Map returns the problem of the first rule matching err, or nil.
+func (m *Mapper) Map(ctx context.Context, path string, err error) Problem {
+	if err == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, rule := range m.rules {
+		if p := rule(ctx, path, err); p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+// Of converts err into a problem like the package-level Of, consulting the rules of the mapper.
+func (m *Mapper) Of(ctx context.Context, path string, err error, f ...MsgFunc) Problem {
+	return of(ctx, m, path, err, f...)
+}
@@ -0,0 +1,76 @@
+package problems
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type conflictError struct {
+	ID string
+}
+
+func (err *conflictError) Error() string {
+	return fmt.Sprintf("user %s already exists", err.ID)
+}
+
+func TestMapper(t *testing.T) {
+	m := NewMapper().
+		Is(sql.ErrNoRows, http.StatusNotFound, Detail("user not found")).
+		As(new(*conflictError), http.StatusConflict, Code("USER_CONFLICT"))
+
+	p := m.Of(context.TODO(), "/users/1", fmt.Errorf("find user: %w", sql.ErrNoRows))
+	if dp, ok := p.(*DefaultProblem); !ok {
+		t.Errorf("expect = DefaultProblem, actual = %v", p)
+	} else {
+		if dp.Status != http.StatusNotFound || dp.Detail != "user not found" || dp.Instance != "/users/1" {
+			t.Errorf("expect = 404 user not found /users/1, actual = %d %s %s", dp.Status, dp.Detail, dp.Instance)
+		}
+	}
+
+	err := fmt.Errorf("create user: %w", &conflictError{ID: "1"})
+	p = m.Of(context.TODO(), "/users", err)
+	if dp, ok := p.(*DefaultProblem); !ok {
+		t.Errorf("expect = DefaultProblem, actual = %v", p)
+	} else {
+		if dp.Status != http.StatusConflict || dp.Code != "USER_CONFLICT" {
+			t.Errorf("expect = 409 USER_CONFLICT, actual = %d %s", dp.Status, dp.Code)
+		}
+		if dp.Detail != "" || !errors.Is(dp.err, err) {
+			t.Errorf("expect = empty detail, actual = %s", dp.Detail)
+		}
+	}
+
+	p = m.Of(context.TODO(), "/users", errors.New("unknown"), func() string { return "custom" })
+	if p.ProblemStatus() != http.StatusInternalServerError {
+		t.Errorf("expect = %d, actual = %d", http.StatusInternalServerError, p.ProblemStatus())
+	}
+
+	p = m.Of(context.TODO(), "/users", New().Forbidden("forbidden").Wrap())
+	if p.ProblemStatus() != http.StatusForbidden {
+		t.Errorf("expect = %d, actual = %d", http.StatusForbidden, p.ProblemStatus())
+	}
+}
+
+func TestDefaultMapper(t *testing.T) {
+	errLocked := errors.New("locked")
+	DefaultMapper.Add(func(ctx context.Context, path string, err error) Problem {
+		if errors.Is(err, errLocked) {
+			return New(Instance(path)).Locked("resource is locked")
+		}
+		return nil
+	})
+	defer func() {
+		DefaultMapper = NewMapper()
+	}()
+	p := Of(context.TODO(), "/users/1", errLocked, func() string { return "custom" })
+	if p.ProblemStatus() != http.StatusLocked {
+		t.Errorf("expect = %d, actual = %d", http.StatusLocked, p.ProblemStatus())
+	}
+	if dp := p.(*DefaultProblem); dp.Detail != "custom" {
+		t.Errorf("expect = custom, actual = %s", dp.Detail)
+	}
+}
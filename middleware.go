@@ -0,0 +1,253 @@
+package problems
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+const (
+	StackKey = "stack"
+)
+
+// PanicError is the error recovered from a panicking handler.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Format prints the stack of the panic with the %+v verb.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	_, _ = io.WriteString(s, e.Error())
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprintf(s, "\n%s", e.Stack)
+	}
+}
+
+// Recoverer recovers panics in next, logs them with their stack and renders the problem converted by Of,
+// a 500 problem unless the panic value is a ProblemError, if the response has not been started.
+func Recoverer(next http.Handler, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw, ww := wrapResponseWriter(w)
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				ctx := requestContext(r)
+				perr := &PanicError{Value: rec, Stack: debug.Stack()}
+				p, ref, _ := convert(ctx, DefaultMapper, r.URL.Path, perr)
+				logPanic(ctx, p, perr, ref)
+				if rw.wroteHeader {
+					return
+				}
+				if dp, ok := p.(DefaultParams); ok {
+					for _, f := range opts {
+						p = f(dp)
+						if dp, ok = p.(DefaultParams); !ok {
+							break
+						}
+					}
+				}
+				Render(ctx, rw, r, p)
+			}
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// logPanic logs the recovered panic with its stack and the reference of its problem.
+func logPanic(ctx context.Context, p Problem, perr *PanicError, ref string) {
+	fields := map[string]interface{}{StackKey: string(perr.Stack)}
+	if ref != "" {
+		fields[ReferenceKey] = ref
+	}
+	loggerOf(p).Log(ctx, Entry{Level: LevelError, Message: perr.Error(), Err: perr, Fields: fields})
+}
+
+// responseWriter records whether the response has been started.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// wrappedWriter is the http.ResponseWriter handed to handlers, exposing the optional interfaces
+// of the inner writer only.
+type wrappedWriter interface {
+	http.ResponseWriter
+	Unwrap() http.ResponseWriter
+	state() *responseWriter
+}
+
+// wrapResponseWriter returns the response state and the writer to pass to handlers,
+// which implements http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom if w does.
+func wrapResponseWriter(w http.ResponseWriter) (*responseWriter, http.ResponseWriter) {
+	if ww, ok := w.(wrappedWriter); ok {
+		return ww.state(), w
+	}
+	rw := &responseWriter{ResponseWriter: w}
+	_, flusher := w.(http.Flusher)
+	_, hijacker := w.(http.Hijacker)
+	_, pusher := w.(http.Pusher)
+	_, readerFrom := w.(io.ReaderFrom)
+	switch {
+	case flusher && hijacker && pusher && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rw, rw, rw, rw, rw}
+	case flusher && hijacker && pusher:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, rw, rw, rw}
+	case flusher && hijacker && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw, rw}
+	case flusher && pusher && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{rw, rw, rw, rw}
+	case hijacker && pusher && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rw, rw, rw, rw}
+	case flusher && hijacker:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, rw, rw}
+	case flusher && pusher:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			http.Pusher
+		}{rw, rw, rw}
+	case flusher && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case hijacker && pusher:
+		return rw, struct {
+			wrappedWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, rw, rw}
+	case hijacker && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case pusher && readerFrom:
+		return rw, struct {
+			wrappedWriter
+			http.Pusher
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case flusher:
+		return rw, struct {
+			wrappedWriter
+			http.Flusher
+		}{rw, rw}
+	case hijacker:
+		return rw, struct {
+			wrappedWriter
+			http.Hijacker
+		}{rw, rw}
+	case pusher:
+		return rw, struct {
+			wrappedWriter
+			http.Pusher
+		}{rw, rw}
+	case readerFrom:
+		return rw, struct {
+			wrappedWriter
+			io.ReaderFrom
+		}{rw, rw}
+	}
+	return rw, struct{ wrappedWriter }{rw}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if status >= http.StatusOK || status == http.StatusSwitchingProtocols {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, buf, err
+}
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+}
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+func (w *responseWriter) state() *responseWriter {
+	return w
+}
+
+// HandlerFunc is an http.Handler whose returned error is converted by Of and rendered
+// with content negotiation.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw, ww := wrapResponseWriter(w)
+	if err := f(ww, r); err != nil {
+		ctx := requestContext(r)
+		p := Of(ctx, r.URL.Path, err)
+		if rw.wroteHeader {
+			return
+		}
+		Render(ctx, rw, r, p)
+	}
+}
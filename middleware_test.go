@@ -0,0 +1,189 @@
+package problems
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+func TestRecoverer(t *testing.T) {
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	}), Type("https://example.com/probs/panic"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expect = %d, actual = %d", http.StatusInternalServerError, w.Code)
+	}
+	if actual := w.Header().Get(headers.ContentType); actual != mimetypes.ProblemJson {
+		t.Errorf("expect = %s, actual = %s", mimetypes.ProblemJson, actual)
+	}
+	p := &DefaultProblem{}
+	if err := json.Unmarshal(w.Body.Bytes(), p); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if p.Instance != "/users/1" {
+		t.Errorf("expect = /users/1, actual = %s", p.Instance)
+	}
+	if p.Type != "https://example.com/probs/panic" {
+		t.Errorf("expect = https://example.com/probs/panic, actual = %s", p.Type)
+	}
+	if p.Detail != "panic: something went wrong" {
+		t.Errorf("expect = panic: something went wrong, actual = %s", p.Detail)
+	}
+}
+
+func TestRecoverer_HeaderWritten(t *testing.T) {
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("something went wrong")
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expect = %d, actual = %d", http.StatusAccepted, w.Code)
+	}
+	if actual := w.Body.String(); actual != "partial" {
+		t.Errorf("expect = partial, actual = %s", actual)
+	}
+}
+
+func TestRecoverer_ProblemError(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(New().Conflict("already exists").Wrap())
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("expect = %d, actual = %d", http.StatusConflict, w.Code)
+	}
+	if len(l.entries) != 1 || l.entries[0].Level != LevelError || l.entries[0].Fields[StackKey] == "" {
+		t.Errorf("expect = %v, actual = %v", "panic entry", l.entries)
+	}
+}
+
+func TestRecoverer_Log(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if len(l.entries) != 1 {
+		t.Fatalf("expect = %v, actual = %v", 1, len(l.entries))
+	}
+	pe := &PanicError{}
+	if e := l.entries[0]; !errors.As(e.Err, &pe) || pe.Value != "something went wrong" || e.Fields[StackKey] != string(pe.Stack) {
+		t.Errorf("expect = %v, actual = %v", "something went wrong", e)
+	}
+}
+
+func TestRecoverer_Abort(t *testing.T) {
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("expect = %v, actual = %v", http.ErrAbortHandler, rec)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestHandlerFunc(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return New(Code("USER_NOT_FOUND")).NotFound("user not found").Wrap()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(headers.Accept, mimetypes.ProblemXml)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expect = %d, actual = %d", http.StatusNotFound, w.Code)
+	}
+	if actual := w.Header().Get(headers.ContentType); actual != mimetypes.ProblemXml {
+		t.Errorf("expect = %s, actual = %s", mimetypes.ProblemXml, actual)
+	}
+	p, err := Bind(req.Context(), w.Code, w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if dp := p.(*DefaultProblem); dp.Instance != "/users/1" || dp.Code != "USER_NOT_FOUND" {
+		t.Errorf("expect = /users/1 USER_NOT_FOUND, actual = %s %s", dp.Instance, dp.Code)
+	}
+}
+
+func TestHandlerFunc_Error(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("connection refused")
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expect = %d, actual = %d", http.StatusInternalServerError, w.Code)
+	}
+	w = httptest.NewRecorder()
+	HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("expect = 200 and empty body, actual = %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRecoverer_Hijack(t *testing.T) {
+	server := httptest.NewServer(Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("expect = http.Hijacker, actual = %T", w)
+			return
+		}
+		conn, buf, err := h.Hijack()
+		if err != nil {
+			t.Errorf("%v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = buf.Flush()
+		panic("after hijack")
+	})))
+	defer server.Close()
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "hijacked" {
+		t.Errorf("expect = %v, actual = %v %s", "hijacked", res.StatusCode, body)
+	}
+}
+
+func TestWrapResponseWriter(t *testing.T) {
+	_, w := wrapResponseWriter(httptest.NewRecorder())
+	if _, ok := w.(http.Hijacker); ok {
+		t.Errorf("expect = %v, actual = %T", "no http.Hijacker", w)
+	}
+	if _, ok := w.(http.Flusher); !ok {
+		t.Errorf("expect = %v, actual = %T", "http.Flusher", w)
+	}
+	if rw, ww := wrapResponseWriter(w); ww != w || rw != w.(wrappedWriter).state() {
+		t.Errorf("expect = %v, actual = %v", w, ww)
+	}
+	_, w = wrapResponseWriter(struct{ http.ResponseWriter }{httptest.NewRecorder()})
+	if _, ok := w.(http.Flusher); ok {
+		t.Errorf("expect = %v, actual = %T", "no http.Flusher", w)
+	}
+}
@@ -0,0 +1,266 @@
+package problems
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+// DefaultMaxDetailLength is the maximum length in runes of a detail taken from a foreign body.
+const DefaultMaxDetailLength = 256
+
+// Format converts a foreign error body into options of the problem, or returns false if it does not recognize it.
+type Format func(status int, body []byte) ([]Option, bool)
+
+// Normalizer converts error responses that are not problems into problems.
+type Normalizer struct {
+	// Formats are tried in order before the built-in formats.
+	Formats []Format
+	// MaxDetailLength limits the detail; DefaultMaxDetailLength is used if zero or less.
+	MaxDetailLength int
+}
+
+// DefaultNormalizer is the normalizer used by Normalize and NormalizeResponse.
+var DefaultNormalizer = &Normalizer{}
+
+// Normalize converts an error response body into a problem using DefaultNormalizer.
+func Normalize(ctx context.Context, status int, contentType string, body []byte) Problem {
+	return DefaultNormalizer.Normalize(ctx, status, contentType, body)
+}
+
+// NormalizeResponse converts an error response into a problem using DefaultNormalizer.
+func NormalizeResponse(res *http.Response) Problem {
+	return DefaultNormalizer.Response(res)
+}
+
+// Response converts a non-2xx response into a problem, or returns nil for other responses.
+// The body is replaced so that it can still be read.
+func (n *Normalizer) Response(res *http.Response) Problem {
+	return n.response(res, DefaultMaxBodySize)
+}
+
+func (n *Normalizer) response(res *http.Response, maxBodySize int64) Problem {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	if res == nil || (res.StatusCode >= 200 && res.StatusCode < 300) {
+		return nil
+	}
+	ctx := context.Background()
+	if res.Request != nil {
+		ctx = res.Request.Context()
+	}
+	var body []byte
+	if res.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+		_ = res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return n.Normalize(ctx, res.StatusCode, res.Header.Get(headers.ContentType), body)
+}
+
+// Normalize converts an error response body into a problem.
+// Problem bodies are bound as is, or reduced to the status if they cannot be decoded; other bodies are
+// recognized as JSON:API, Google API, OAuth2 or generic JSON errors, HTML or plain text.
+func (n *Normalizer) Normalize(ctx context.Context, status int, contentType string, body []byte) Problem {
+	var opts []Option
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == mimetypes.ProblemJson || mediaType == mimetypes.ProblemXml {
+		p, err := Bind(ctx, status, body)
+		if err == nil {
+			return p
+		}
+	} else if o, ok := n.recognize(status, mediaType, body); ok {
+		opts = o
+	} else {
+		opts = []Option{Detail(n.sanitize(string(body)))}
+	}
+	b := New(opts...).internal()
+	return b.build(status, "", b.f...)
+}
+
+func (n *Normalizer) recognize(status int, mediaType string, body []byte) ([]Option, bool) {
+	for _, f := range n.Formats {
+		if opts, ok := f(status, body); ok {
+			return opts, true
+		}
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case len(trimmed) == 0:
+		return nil, true
+	case json.Valid(trimmed):
+		for _, f := range []Format{jsonAPIFormat, googleFormat, oauth2Format, messageFormat} {
+			if opts, ok := f(status, trimmed); ok {
+				return n.sanitizeDetail(opts), true
+			}
+		}
+	case mediaType == "text/html" || isHTML(trimmed):
+		return []Option{Detail(n.sanitize(htmlText(string(trimmed))))}, true
+	}
+	return nil, false
+}
+
+// sanitizeDetail appends an option truncating and sanitizing the detail set by opts.
+func (n *Normalizer) sanitizeDetail(opts []Option) []Option {
+	return append(opts, func(p DefaultParams) Problem {
+		if dp := defaultProblemOf(p.(Problem)); dp != nil {
+			dp.Detail = n.sanitize(dp.Detail)
+		}
+		return p.(Problem)
+	})
+}
+
+func (n *Normalizer) sanitize(s string) string {
+	max := n.MaxDetailLength
+	if max <= 0 {
+		max = DefaultMaxDetailLength
+	}
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	s = strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+	if utf8.RuneCountInString(s) > max {
+		s = string([]rune(s)[:max]) + "..."
+	}
+	return s
+}
+
+var (
+	htmlTitle   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlIgnored = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+func isHTML(body []byte) bool {
+	prefix := strings.ToLower(string(body[:min(len(body), 64)]))
+	return strings.HasPrefix(prefix, "<!doctype html") || strings.HasPrefix(prefix, "<html")
+}
+
+// htmlText returns the title of an HTML page, or its text without tags.
+func htmlText(s string) string {
+	if m := htmlTitle.FindStringSubmatch(s); m != nil && strings.TrimSpace(m[1]) != "" {
+		return html.UnescapeString(m[1])
+	}
+	s = htmlIgnored.ReplaceAllString(s, " ")
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+}
+
+// jsonAPIFormat recognizes JSON:API error objects.
+func jsonAPIFormat(status int, body []byte) ([]Option, bool) {
+	var doc struct {
+		Errors []struct {
+			Status string `json:"status"`
+			Code   string `json:"code"`
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+			Source struct {
+				Pointer   string `json:"pointer"`
+				Parameter string `json:"parameter"`
+			} `json:"source"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || len(doc.Errors) == 0 {
+		return nil, false
+	}
+	first := doc.Errors[0]
+	if first.Title == "" && first.Detail == "" && first.Code == "" {
+		return nil, false
+	}
+	var opts []Option
+	if first.Title != "" {
+		opts = append(opts, Title(first.Title))
+	}
+	if first.Detail != "" {
+		opts = append(opts, Detail(first.Detail))
+	}
+	if first.Code != "" {
+		opts = append(opts, Code(first.Code))
+	}
+	var verrs []ValidationError
+	for _, e := range doc.Errors {
+		pointer := e.Source.Pointer
+		if pointer == "" && e.Source.Parameter != "" {
+			pointer = fieldToPointer(e.Source.Parameter)
+		} else if pointer != "" && !strings.HasPrefix(pointer, "#") {
+			pointer = "#" + pointer
+		}
+		if pointer != "" {
+			detail := e.Detail
+			if detail == "" {
+				detail = e.Title
+			}
+			verrs = append(verrs, ValidationError{Detail: detail, Pointer: pointer})
+		}
+	}
+	if len(verrs) > 0 {
+		opts = append(opts, ValidationErrors(nil, verrs...))
+	}
+	return opts, true
+}
+
+// googleFormat recognizes Google API errors.
+func googleFormat(status int, body []byte) ([]Option, bool) {
+	var doc struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || doc.Error == nil || doc.Error.Message == "" {
+		return nil, false
+	}
+	opts := []Option{Detail(doc.Error.Message)}
+	if doc.Error.Status != "" {
+		opts = append(opts, Code(doc.Error.Status))
+	} else if doc.Error.Code != 0 {
+		opts = append(opts, Code(strconv.Itoa(doc.Error.Code)))
+	}
+	return opts, true
+}
+
+// oauth2Format recognizes OAuth2 error responses (RFC6749 section 5.2).
+func oauth2Format(status int, body []byte) ([]Option, bool) {
+	var doc struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		ErrorURI         string `json:"error_uri"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || doc.Error == "" || doc.ErrorDescription == "" && doc.ErrorURI == "" {
+		return nil, false
+	}
+	opts := []Option{Code(doc.Error), Detail(doc.ErrorDescription)}
+	if doc.ErrorURI != "" {
+		opts = append(opts, Type("%s", doc.ErrorURI))
+	}
+	return opts, true
+}
+
+// messageFormat recognizes objects with a message, error or detail string member.
+func messageFormat(status int, body []byte) ([]Option, bool) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, false
+	}
+	for _, key := range []string{"message", "error", "detail", "error_message", "msg"} {
+		if s, ok := doc[key].(string); ok && s != "" {
+			return []Option{Detail(s)}, true
+		}
+	}
+	return nil, false
+}
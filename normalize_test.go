@@ -0,0 +1,110 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		title       string
+		detail      string
+		code        string
+	}{
+		{name: "problem", status: 404, contentType: "application/problem+json", body: `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found"}`, title: "Not Found", detail: "user not found"},
+		{name: "jsonapi", status: 422, contentType: "application/vnd.api+json", body: `{"errors":[{"status":"422","code":"INVALID","title":"Invalid Attribute","detail":"must be at least 3 characters","source":{"pointer":"/data/attributes/name"}}]}`, title: "Invalid Attribute", detail: "must be at least 3 characters", code: "INVALID"},
+		{name: "google", status: 403, contentType: "application/json", body: `{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`, title: "Forbidden", detail: "The caller does not have permission", code: "PERMISSION_DENIED"},
+		{name: "oauth2", status: 400, contentType: "application/json", body: `{"error":"invalid_grant","error_description":"refresh token expired"}`, title: "Bad Request", detail: "refresh token expired", code: "invalid_grant"},
+		{name: "message", status: 500, contentType: "application/json", body: `{"message":"database\nunavailable"}`, title: "Internal Server Error", detail: "database unavailable"},
+		{name: "html", status: 502, contentType: "text/html", body: `<html><head><title>502 Bad Gateway</title></head><body><h1>502 Bad Gateway</h1></body></html>`, title: "Bad Gateway", detail: "502 Bad Gateway"},
+		{name: "html body", status: 503, contentType: "text/html; charset=utf-8", body: `<html><body><script>x()</script><p>Service &amp; maintenance</p></body></html>`, title: "Service Unavailable", detail: "Service & maintenance"},
+		{name: "text", status: 500, contentType: "text/plain", body: "panic:\tnil pointer\r\n", title: "Internal Server Error", detail: "panic: nil pointer"},
+		{name: "empty", status: 504, title: "Gateway Timeout"},
+		{name: "invalid problem", status: 502, contentType: "application/problem+json", body: `{"detail":"upstream failure"`, title: "Bad Gateway"},
+	}
+	for _, tt := range tests {
+		p := Normalize(context.TODO(), tt.status, tt.contentType, []byte(tt.body))
+		dp := defaultProblemOf(p)
+		if dp.Status != tt.status || dp.Title != tt.title || dp.Detail != tt.detail || dp.Code != tt.code {
+			t.Errorf("%s: expect = %d %s %s %s, actual = %d %s %s %s", tt.name, tt.status, tt.title, tt.detail, tt.code, dp.Status, dp.Title, dp.Detail, dp.Code)
+		}
+	}
+	p := Normalize(context.TODO(), 422, "application/vnd.api+json", []byte(`{"errors":[{"title":"Invalid","source":{"pointer":"/name"}}]}`))
+	if br := badRequestOf(p); br == nil || len(br.Errors) != 1 || br.Errors[0].Pointer != "#/name" {
+		t.Errorf("expect = %v, actual = %v", "#/name", p)
+	}
+}
+
+func TestNormalizer_Truncate(t *testing.T) {
+	n := &Normalizer{MaxDetailLength: 10}
+	p := n.Normalize(context.TODO(), 500, "text/plain", []byte(strings.Repeat("a", 20)))
+	if dp := defaultProblemOf(p); dp.Detail != strings.Repeat("a", 10)+"..." {
+		t.Errorf("expect = %v, actual = %v", strings.Repeat("a", 10)+"...", dp.Detail)
+	}
+}
+
+func TestNormalizer_Formats(t *testing.T) {
+	n := &Normalizer{Formats: []Format{func(status int, body []byte) ([]Option, bool) {
+		if !strings.HasPrefix(string(body), "ERR ") {
+			return nil, false
+		}
+		return []Option{Code(strings.TrimPrefix(string(body), "ERR "))}, true
+	}}}
+	p := n.Normalize(context.TODO(), 500, "text/plain", []byte("ERR E42"))
+	if dp := defaultProblemOf(p); dp.Code != "E42" {
+		t.Errorf("expect = %v, actual = %v", "E42", dp.Code)
+	}
+}
+
+func TestTransport_Normalizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream failure", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{Normalizer: DefaultNormalizer}}
+	_, err := client.Get(srv.URL)
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) {
+		t.Fatalf("expect = ProblemError, actual = %v", err)
+	}
+	if dp := defaultProblemOf(pe.Problem()); dp.Status != http.StatusBadGateway || dp.Detail != "upstream failure" {
+		t.Errorf("expect = 502 upstream failure, actual = %d %s", dp.Status, dp.Detail)
+	}
+}
+
+func TestTransport_NormalizerFallback(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		detail      string
+	}{
+		{contentType: "application/problem+json", body: `{"status":`},
+		{contentType: "application/problem+json", body: `{"detail":"` + strings.Repeat("a", 20) + `"}`},
+		{contentType: "text/plain", body: strings.Repeat("a", 20), detail: strings.Repeat("a", 8)},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(tt.body))
+		}))
+		client := &http.Client{Transport: &Transport{MaxBodySize: 8, Normalizer: DefaultNormalizer}}
+		_, err := client.Get(srv.URL)
+		srv.Close()
+		pe := &ProblemError{}
+		if !errors.As(err, &pe) {
+			t.Fatalf("expect = ProblemError, actual = %v", err)
+		}
+		if dp := defaultProblemOf(pe.Problem()); dp.Status != http.StatusBadGateway || dp.Detail != tt.detail {
+			t.Errorf("expect = 502 %s, actual = %d %s", tt.detail, dp.Status, dp.Detail)
+		}
+	}
+}
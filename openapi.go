@@ -0,0 +1,63 @@
+package problems
+
+import (
+	"net/http"
+
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+// OpenAPIComponents are the OpenAPI 3.1 components describing problem types.
+type OpenAPIComponents struct {
+	Schemas   map[string]*Schema          `json:"schemas"`
+	Responses map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIResponse is an OpenAPI Response Object.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType is an OpenAPI Media Type Object.
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPI returns the schemas and responses of the built-in problems and of the types registered in r,
+// with application/problem+json and application/problem+xml content.
+// DefaultRegistry is used if r is nil.
+// Registered types are named after the last segment of their type URI.
+func OpenAPI(r *Registry) *OpenAPIComponents {
+	if r == nil {
+		r = DefaultRegistry
+	}
+	g := newSchemaGenerator("#/components/schemas/")
+	schemas, types := g.definitions(r)
+	c := &OpenAPIComponents{
+		Schemas:   schemas,
+		Responses: make(map[string]*OpenAPIResponse),
+	}
+	c.Responses[problemSchema] = openAPIResponse("Problem details.", g.ref(problemSchema))
+	c.Responses[badRequestSchema] = openAPIResponse(http.StatusText(http.StatusBadRequest), g.ref(badRequestSchema))
+	for _, t := range types {
+		description := t.t.Title
+		if description == "" {
+			description = http.StatusText(t.t.Status)
+		}
+		if description == "" {
+			description = t.t.Type
+		}
+		c.Responses[t.name] = openAPIResponse(description, g.ref(t.name))
+	}
+	return c
+}
+
+func openAPIResponse(description string, schema *Schema) *OpenAPIResponse {
+	return &OpenAPIResponse{
+		Description: description,
+		Content: map[string]OpenAPIMediaType{
+			mimetypes.ProblemJson: {Schema: schema},
+			mimetypes.ProblemXml:  {Schema: schema},
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package problems
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+func TestOpenAPI(t *testing.T) {
+	c := OpenAPI(outOfCreditRegistry())
+	for _, name := range []string{"Problem", "BadRequest", "InvalidParam", "ValidationError", "OutOfCredit"} {
+		if _, ok := c.Schemas[name]; !ok {
+			t.Errorf("expect = %v, actual = %v", name, c.Schemas)
+		}
+	}
+	s := c.Schemas["OutOfCredit"]
+	if len(s.AllOf) != 3 || s.AllOf[0].Ref != "#/components/schemas/Problem" {
+		t.Fatalf("expect = %v, actual = %v", "#/components/schemas/Problem", s.AllOf)
+	}
+	if s.AllOf[1].Properties["balance"].Type != "integer" {
+		t.Errorf("expect = %v, actual = %v", "integer", s.AllOf[1].Properties["balance"])
+	}
+	members := s.AllOf[2].Properties
+	if members["type"].Const != outOfCreditType || members["status"].Default != http.StatusForbidden {
+		t.Errorf("expect = %v, actual = %v", outOfCreditType, members)
+	}
+	res := c.Responses["OutOfCredit"]
+	if res.Description != "You do not have enough credit." {
+		t.Errorf("expect = %v, actual = %v", "You do not have enough credit.", res.Description)
+	}
+	for _, mediaType := range []string{mimetypes.ProblemJson, mimetypes.ProblemXml} {
+		if res.Content[mediaType].Schema.Ref != "#/components/schemas/OutOfCredit" {
+			t.Errorf("expect = %v, actual = %v", "#/components/schemas/OutOfCredit", res.Content[mediaType])
+		}
+	}
+	if c.Schemas["Problem"].XML.Namespace != XmlNamespace {
+		t.Errorf("expect = %v, actual = %v", XmlNamespace, c.Schemas["Problem"].XML)
+	}
+	if _, err := json.Marshal(c); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestOpenAPI_Names(t *testing.T) {
+	r := NewRegistry()
+	f := func() Problem { return &DefaultProblem{} }
+	_ = r.Register("https://example.com/a/problem", f)
+	_ = r.Register("https://example.com/b/problem", f)
+	_ = r.Register("tag:example.com,2024:bad-request", f)
+	c := OpenAPI(r)
+	for _, name := range []string{"Problem2", "Problem3", "BadRequest2"} {
+		if s, ok := c.Schemas[name]; !ok || s.AllOf[0].Ref != "#/components/schemas/Problem" {
+			t.Errorf("expect = %v, actual = %v", name, s)
+		}
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/probs/out-of-credit": "OutOfCredit",
+		"https://example.com/probs/user_missing/": "UserMissing",
+		"/errors/quota.html":                      "Quota",
+		"urn:problem:rate-limited":                "RateLimited",
+		"https://example.com/404":                 "Problem404",
+	}
+	for uri, expect := range tests {
+		if actual := typeName(uri); actual != expect {
+			t.Errorf("expect = %v, actual = %v", expect, actual)
+		}
+	}
+}
@@ -10,17 +10,19 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/goccha/http-constants/pkg/headers"
 	"github.com/goccha/http-constants/pkg/mimetypes"
-	"github.com/goccha/logging/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	DefaultType = "about:blank"
+	DefaultType  = "about:blank"
+	XmlNamespace = "urn:ietf:rfc:7807"
 )
 
 type Renderer interface {
@@ -29,7 +31,8 @@ type Renderer interface {
 	Wrap() error
 }
 
-func setHeader(ctx context.Context, w http.ResponseWriter, status int, mimetype string) {
+func setHeader(ctx context.Context, w http.ResponseWriter, status int, mimetype string, v interface{}) {
+	writeHeaders(w, v)
 	w.Header().Set(headers.ContentType, mimetype)
 	if status > 0 {
 		w.WriteHeader(status)
@@ -39,19 +42,50 @@ func setHeader(ctx context.Context, w http.ResponseWriter, status int, mimetype
 }
 
 func WriteJson(ctx context.Context, w http.ResponseWriter, status int, v interface{}) {
-	setHeader(ctx, w, status, mimetypes.ProblemJson)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.EmbedObject(ctx, log.Warn(ctx).Err(err)).Send()
+	setHeader(ctx, w, status, mimetypes.ProblemJson, v)
+	bin, err := marshalJSON(v)
+	if err == nil {
+		_, err = w.Write(append(bin, '\n'))
+	}
+	if err != nil {
+		loggerOf(v).Log(ctx, Entry{Level: LevelWarn, Err: err})
 	}
 }
 
+// WriteXml writes problems in the XML format of RFC7807 Appendix A; other values are encoded by encoding/xml.
 func WriteXml(ctx context.Context, w http.ResponseWriter, status int, v interface{}) {
-	setHeader(ctx, w, status, mimetypes.ProblemXml)
-	if err := xml.NewEncoder(w).Encode(v); err != nil {
-		log.EmbedObject(ctx, log.Warn(ctx).Err(err)).Send()
+	var err error
+	switch v.(type) {
+	case Problem, ExtensionParameter:
+		var bin []byte
+		if bin, err = marshalXML(v); err != nil {
+			// nothing has been written, so a valid document of the problem members is sent instead
+			loggerOf(v).Log(ctx, Entry{Level: LevelWarn, Err: err})
+			bin, err = marshalXML(problemMembers(v))
+		}
+		setHeader(ctx, w, status, mimetypes.ProblemXml, v)
+		if err == nil {
+			_, err = w.Write(bin)
+		}
+	default:
+		setHeader(ctx, w, status, mimetypes.ProblemXml, v)
+		err = xml.NewEncoder(w).Encode(v)
+	}
+	if err != nil {
+		loggerOf(v).Log(ctx, Entry{Level: LevelWarn, Err: err})
 	}
 }
 
+// problemMembers returns the standard members of v without its extension members.
+func problemMembers(v interface{}) interface{} {
+	if p, ok := v.(Problem); ok {
+		if dp := defaultProblemOf(p); dp != nil {
+			return &DefaultProblem{Type: dp.Type, Title: dp.Title, Status: dp.Status, Detail: dp.Detail, Instance: dp.Instance, Code: dp.Code}
+		}
+	}
+	return struct{}{}
+}
+
 type Problem interface {
 	ProblemStatus() int
 	Wrap() error
@@ -76,14 +110,25 @@ type Wrapper interface {
 	WrapError(err error)
 }
 
+// ExtensionParameter is implemented by problems that carry RFC9457 extension members.
+type ExtensionParameter interface {
+	SetExtension(key string, value interface{})
+	Extensions() map[string]interface{}
+}
+
 type DefaultProblem struct {
-	Type     string `json:"type"`
-	Title    string `json:"title"`
-	Status   int    `json:"status,omitempty"`
-	Detail   string `json:"detail,omitempty"`
-	Instance string `json:"instance,omitempty"`
-	Code     string `json:"code,omitempty"`
-	err      error
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Instance   string `json:"instance,omitempty"`
+	Code       string `json:"code,omitempty"`
+	extensions map[string]interface{}
+	err        error
+	logger     Logger
+	retryAfter *retryAfter
+	header     http.Header
+	translator ut.Translator
 }
 
 func (p *DefaultProblem) WrapError(err error) {
@@ -110,9 +155,26 @@ func (p *DefaultProblem) SetDetail(detail string) {
 func (p *DefaultProblem) SetInstance(instance string) {
 	p.Instance = instance
 }
+func (p *DefaultProblem) SetStatus(status int) {
+	p.Status = status
+}
 func (p *DefaultProblem) SetCode(code string) {
 	p.Code = code
 }
+func (p *DefaultProblem) SetExtension(key string, value interface{}) {
+	if p.extensions == nil {
+		p.extensions = make(map[string]interface{})
+	}
+	p.extensions[key] = value
+}
+
+// Extensions returns the extension members, which are flattened into the top-level object when rendered.
+func (p *DefaultProblem) Extensions() map[string]interface{} {
+	if p == nil {
+		return nil
+	}
+	return p.extensions
+}
 func (p *DefaultProblem) ProblemStatus() int {
 	return p.Status
 }
@@ -127,7 +189,7 @@ func (p *DefaultProblem) Wrap() error {
 	return &ProblemError{problem: p, err: p.err}
 }
 func (p *DefaultProblem) String() string {
-	bytes, err := json.Marshal(p)
+	bytes, err := marshalJSON(p)
 	if err != nil {
 		return err.Error()
 	}
@@ -141,8 +203,13 @@ func NewProblem(status int) *DefaultProblem {
 	return p
 }
 
+// WrapProblem returns the problem as an error, which unwraps to the error wrapped by the problem.
 func WrapProblem(p Problem) error {
-	return &ProblemError{problem: p}
+	pe := &ProblemError{problem: p}
+	if dp := defaultProblemOf(p); dp != nil {
+		pe.err = dp.err
+	}
+	return pe
 }
 
 func WrapError(err error) error {
@@ -150,21 +217,32 @@ func WrapError(err error) error {
 }
 
 type ProblemError struct {
-	Path    string
-	problem Problem
-	err     error
+	Path string
+	// Response is the HTTP response the problem was decoded from, if any.
+	Response *http.Response
+	problem  Problem
+	err      error
+	// status is the gRPC status the problem was converted from, if any.
+	status *status.Status
+	// built caches the problem of err, so that it is redacted and logged once.
+	once  sync.Once
+	built Problem
 }
 
 func (err *ProblemError) Problem() Problem {
-	if err.err != nil {
-		return New(Instance(err.Path)).InternalServerError(err.err.Error())
+	p := err.problem
+	if p == nil && err.err != nil {
+		err.once.Do(func() {
+			err.built = New(Instance(err.Path), Wrap(err.err)).InternalServerError(err.err.Error())
+		})
+		p = err.built
 	}
-	if v, ok := err.problem.(DefaultParams); ok {
+	if v, ok := p.(DefaultParams); ok {
 		if err.Path != "" {
 			v.SetInstance(err.Path)
 		}
 	}
-	return err.problem
+	return p
 }
 func (err *ProblemError) Error() string {
 	if err.err != nil {
@@ -179,10 +257,34 @@ func (err *ProblemError) Unwrap() error {
 	return nil
 }
 
+func (p *DefaultProblem) defaultProblem() *DefaultProblem {
+	return p
+}
+func (p *BadRequest) badRequest() *BadRequest {
+	return p
+}
+
+func defaultProblemOf(p Problem) *DefaultProblem {
+	if v, ok := p.(interface{ defaultProblem() *DefaultProblem }); ok {
+		return v.defaultProblem()
+	}
+	return nil
+}
+
+func badRequestOf(p Problem) *BadRequest {
+	if v, ok := p.(interface{ badRequest() *BadRequest }); ok {
+		return v.badRequest()
+	}
+	return nil
+}
+
 type BadRequest struct {
 	*DefaultProblem
 	InvalidParams []InvalidParam    `json:"invalid-params,omitempty"`
 	Errors        []ValidationError `json:"errors,omitempty"`
+	// validator errors by index of InvalidParams and Errors, kept for translation
+	paramErrors map[int]validator.FieldError
+	fieldErrors map[int]validator.FieldError
 }
 
 func (p *BadRequest) JSON(ctx context.Context, w http.ResponseWriter) {
@@ -223,12 +325,14 @@ func InvalidParams(err error, params ...InvalidParam) Option {
 		}
 		switch bp := p.(type) {
 		case *BadRequest:
+			bp.paramErrors = recordFieldErrors(bp.paramErrors, len(bp.InvalidParams), ve)
 			bp.InvalidParams = append(bp.InvalidParams, fields...)
 			return bp
 		case *DefaultProblem:
 			return &BadRequest{
 				DefaultProblem: p.(*DefaultProblem),
 				InvalidParams:  fields,
+				paramErrors:    recordFieldErrors(nil, 0, ve),
 			}
 		}
 		return p
@@ -282,12 +386,14 @@ func ValidationErrors(err error, verrs ...ValidationError) Option {
 		}
 		switch bp := p.(type) {
 		case *BadRequest:
+			bp.fieldErrors = recordFieldErrors(bp.fieldErrors, len(bp.Errors), ve)
 			bp.Errors = append(bp.Errors, fields...)
 			return bp
 		case *DefaultProblem:
 			return &BadRequest{
 				DefaultProblem: p.(*DefaultProblem),
 				Errors:         fields,
+				fieldErrors:    recordFieldErrors(nil, 0, ve),
 			}
 		}
 		return p
@@ -340,6 +446,16 @@ func Wrap(err error) Option {
 	}
 }
 
+// Extension adds an RFC9457 extension member to the problem.
+func Extension(key string, value interface{}) Option {
+	return func(p DefaultParams) Problem {
+		if ep, ok := p.(ExtensionParameter); ok {
+			ep.SetExtension(key, value)
+		}
+		return p
+	}
+}
+
 type InvalidParam struct {
 	Name   string `json:"name"`
 	Reason string `json:"reason"`
@@ -369,33 +485,67 @@ func selectMsg(err error, f ...MsgFunc) MsgFunc {
 	}
 }
 
+// Of converts err into a problem, consulting the rules of DefaultMapper before the default conversion.
 func Of(ctx context.Context, path string, err error, f ...MsgFunc) Problem {
+	return of(ctx, DefaultMapper, path, err, f...)
+}
+
+func of(ctx context.Context, m *Mapper, path string, err error, f ...MsgFunc) Problem {
+	p, ref, ok := convert(ctx, m, path, err, f...)
+	if ok {
+		DefaultLogPolicy.log(ctx, p, err, ref)
+	}
+	return p
+}
+
+// convert converts err into a problem with its reference, redacted in Production mode.
+// It reports false for a ProblemError, whose problem is not logged by Of.
+func convert(ctx context.Context, m *Mapper, path string, err error, f ...MsgFunc) (Problem, string, bool) {
 	pe := &ProblemError{}
 	if errors.As(err, &pe) {
 		if pe.Path == "" {
 			pe.Path = path
 		}
-		return pe.Problem()
-	}
-	msg := selectMsg(err, f...)
-	if st, ok := status.FromError(errors.Unwrap(err)); ok {
-		switch st.Code() {
-		case codes.Unavailable:
-			log.EmbedObject(ctx, log.Warn(ctx, 1)).Stack().Msgf("%+v", err)
-			return New(Instance(path)).Unavailable(msg())
+		p := pe.Problem()
+		return p, referenceOf(p), false
+	}
+	var p Problem
+	if p = m.Map(ctx, path, err); p != nil {
+		if dp, ok := p.(DefaultParams); ok && len(f) > 0 {
+			dp.SetDetail(f[0]())
+		}
+	} else if st, ok := status.FromError(err); ok && st.Code() != codes.OK {
+		detail := st.Message()
+		if len(f) > 0 {
+			detail = f[0]()
 		}
+		p = fromStatus(st, m.HTTPStatus(st.Code()), detail, Instance(path))
+	} else {
+		msg := selectMsg(err, f...)
+		p = New(Instance(path), Wrap(err)).internal().InternalServerError(msg())
 	}
-	log.EmbedObject(ctx, log.Error(ctx, 1)).Stack().Err(err).Msgf("%+v", err)
-	return New(Instance(path), Wrap(err)).InternalServerError(msg())
+	ref := referenceOf(p)
+	if ref == "" && (referenceEnabled.Load() || currentMode() == Production && p.ProblemStatus() >= http.StatusInternalServerError) {
+		ref = ReferenceFrom(ctx)
+		if ref == "" {
+			ref = newReference()
+		}
+		ref = setReference(p, ref)
+	}
+	if currentMode() == Production {
+		redact(p)
+	}
+	return p, ref, true
 }
 
+// Bind decodes body into the problem returned by f, or else into the type registered for its type URI.
 func Bind(ctx context.Context, status int, body []byte, f ...func(status int) Problem) (problem Problem, err error) {
-	problem = newProblem(status, f...)
+	problem = newProblem(status, peekType(body), f...)
 	if len(body) <= 0 {
 		return
 	}
-	if err = json.Unmarshal(body, problem); err != nil {
-		log.Error(ctx).Msg(string(body))
+	if err = unmarshalProblem(body, problem); err != nil {
+		currentLogger().Log(ctx, Entry{Level: LevelError, Message: string(body), Err: err})
 		return problem, fmt.Errorf("%w", err)
 	}
 	if decoder, ok := problem.(GraphQLDecoder); ok {
@@ -409,14 +559,19 @@ func Bind(ctx context.Context, status int, body []byte, f ...func(status int) Pr
 	return
 }
 
-func newProblem(status int, f ...func(status int) Problem) (problem Problem) {
+// newProblem instantiates the problem returned by f, or else the problem registered for typ,
+// falling back to the status-based selection.
+func newProblem(status int, typ string, f ...func(status int) Problem) (problem Problem) {
 	if len(f) > 0 {
 		problem = f[0](status)
 	}
+	if problem == nil && typ != "" {
+		problem = DefaultRegistry.Create(typ)
+	}
 	if problem == nil {
 		switch status {
 		case http.StatusBadRequest:
-			problem = &BadRequest{}
+			problem = &BadRequest{DefaultProblem: &DefaultProblem{}}
 		default:
 			problem = &DefaultProblem{}
 		}
@@ -424,13 +579,17 @@ func newProblem(status int, f ...func(status int) Problem) (problem Problem) {
 	return
 }
 
+// Decode decodes body into the problem returned by f, or else into the type registered for its type URI.
 func Decode(ctx context.Context, status int, body io.Reader, f ...func(status int) Problem) (problem Problem, err error) {
-	problem = newProblem(status, f...)
 	if body == nil {
-		return
+		return newProblem(status, "", f...), nil
+	}
+	bin, err := io.ReadAll(body)
+	problem = newProblem(status, peekType(bin), f...)
+	if err != nil {
+		return problem, fmt.Errorf("%w", err)
 	}
-	if err = json.NewDecoder(body).Decode(&problem); err != nil {
-		_, _ = io.Copy(io.Discard, body)
+	if err = unmarshalProblem(bin, problem); err != nil {
 		return problem, fmt.Errorf("%w", err)
 	}
 	return
@@ -176,6 +176,19 @@ func TestOf(t *testing.T) {
 	}
 }
 
+func TestProblemError_Cause(t *testing.T) {
+	cause := errors.New("user 1 not found")
+	p := New(Wrap(cause)).NotFound("user not found")
+	for _, err := range []error{p.Wrap(), WrapProblem(p)} {
+		pe := &ProblemError{}
+		if !errors.Is(err, cause) || !errors.As(err, &pe) {
+			t.Errorf("expect = %v, actual = %v", cause, err)
+		} else if pe.Problem() != p {
+			t.Errorf("expect = %v, actual = %v", p, pe.Problem())
+		}
+	}
+}
+
 func TestOfNil(t *testing.T) {
 	p := Of(context.TODO(), "/problems", nil)
 	if dp, ok := p.(*DefaultProblem); ok {
@@ -467,3 +480,59 @@ func (fe *fieldError) Error() string {
 func (fe *fieldError) Translate(ut ut.Translator) string {
 	return ""
 }
+
+func TestExtension(t *testing.T) {
+	p := New(Instance("/problems"), Extension("balance", 30), Extension("accounts", []string{"/account/12345"})).Forbidden("insufficient credit")
+	actual := p.String()
+	expect := `{"type":"about:blank","title":"Forbidden","status":403,"detail":"insufficient credit","instance":"/problems","accounts":["/account/12345"],"balance":30}`
+	if actual != expect {
+		t.Errorf("expect = %s, actual = %s", expect, actual)
+	}
+	if bp, err := Bind(context.TODO(), http.StatusForbidden, []byte(actual)); err != nil {
+		t.Errorf("%v", err)
+	} else {
+		dp := bp.(*DefaultProblem)
+		if dp.Extensions()["balance"] != float64(30) {
+			t.Errorf("expect = 30, actual = %v", dp.Extensions()["balance"])
+		}
+		if _, ok := dp.Extensions()["detail"]; ok {
+			t.Errorf("expect = no detail extension, actual = %v", dp.Extensions())
+		}
+	}
+	if bp, err := Decode(context.TODO(), http.StatusForbidden, strings.NewReader(actual)); err != nil {
+		t.Errorf("%v", err)
+	} else if bp.String() != expect {
+		t.Errorf("expect = %s, actual = %s", expect, bp.String())
+	}
+}
+
+func TestExtension_BadRequest(t *testing.T) {
+	p := New(Extension("trace", "abc"), ValidationErrors(nil, ValidationError{Detail: "required", Pointer: "#/name"})).BadRequest("bad request")
+	bin, err := marshalJSON(p)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	bp, err := Bind(context.TODO(), http.StatusBadRequest, bin)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	br := bp.(*BadRequest)
+	if len(br.Errors) != 1 {
+		t.Errorf("expect = 1, actual = %d", len(br.Errors))
+	}
+	if len(br.Extensions()) != 1 || br.Extensions()["trace"] != "abc" {
+		t.Errorf("expect = map[trace:abc], actual = %v", br.Extensions())
+	}
+}
+
+func TestExtension_XML(t *testing.T) {
+	p := New(Extension("balance", 30), Extension("accounts", []string{"/account/1"})).Forbidden("insufficient credit")
+	w := httptest.NewRecorder()
+	p.XML(context.TODO(), w)
+	body := w.Body.String()
+	for _, expect := range []string{"<balance>30</balance>", "<accounts><i>/account/1</i></accounts>"} {
+		if !strings.Contains(body, expect) {
+			t.Errorf("expect = %s, actual = %s", expect, body)
+		}
+	}
+}
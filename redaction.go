@@ -0,0 +1,50 @@
+package problems
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Mode controls whether internal error details are exposed in 5xx problems.
+type Mode int32
+
+const (
+	// Development keeps the detail of 5xx problems.
+	Development Mode = iota + 1
+	// Production replaces the detail of 5xx problems with RedactedDetail and a reference.
+	Production
+)
+
+// RedactedDetail is the detail of 5xx problems in Production mode.
+var RedactedDetail = "An internal error occurred. Please contact support with the reference."
+
+var mode atomic.Int32
+
+func init() {
+	mode.Store(int32(Development))
+}
+
+// SetMode sets the mode used by Of and by builders without their own mode.
+func SetMode(m Mode) {
+	mode.Store(int32(m))
+}
+
+func currentMode() Mode {
+	return Mode(mode.Load())
+}
+
+// redact replaces the detail of a 5xx problem, reusing its reference or generating a new one.
+// It reports whether the detail was replaced.
+func redact(p Problem) (ref string, redacted bool) {
+	dp := defaultProblemOf(p)
+	if dp == nil || p.ProblemStatus() < http.StatusInternalServerError {
+		return "", false
+	}
+	ref, _ = dp.Extensions()[ReferenceKey].(string)
+	if ref == "" {
+		ref = newReference()
+		dp.SetExtension(ReferenceKey, ref)
+	}
+	dp.Detail = RedactedDetail
+	return ref, true
+}
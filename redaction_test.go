@@ -0,0 +1,80 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	SetMode(Production)
+	defer SetMode(Development)
+
+	p := Of(context.TODO(), "/users", errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	dp := defaultProblemOf(p)
+	if dp.Status != http.StatusInternalServerError || dp.Detail != RedactedDetail {
+		t.Errorf("expect = %v, actual = %v", RedactedDetail, dp.Detail)
+	}
+	if ref, _ := dp.Extensions()[ReferenceKey].(string); len(ref) != 32 {
+		t.Errorf("expect = reference, actual = %v", dp.Extensions()[ReferenceKey])
+	}
+
+	p = WrapError(errors.New("secret")).(*ProblemError).Problem()
+	if dp = defaultProblemOf(p); dp.Detail != RedactedDetail || dp.Extensions()[ReferenceKey] == nil {
+		t.Errorf("expect = %v, actual = %v", RedactedDetail, dp.Detail)
+	}
+
+	p = New().NotFound("user not found")
+	if dp = defaultProblemOf(p); dp.Detail != "user not found" || dp.Extensions()[ReferenceKey] != nil {
+		t.Errorf("expect = %v, actual = %v", "user not found", dp.Detail)
+	}
+
+	p = New(Extension(ReferenceKey, "ref-1")).Unavailable("maintenance")
+	if dp = defaultProblemOf(p); dp.Detail != RedactedDetail || dp.Extensions()[ReferenceKey] != "ref-1" {
+		t.Errorf("expect = %v, actual = %v", "ref-1", dp.Extensions()[ReferenceKey])
+	}
+
+	p = New().Mode(Development).InternalServerError("verbose")
+	if dp = defaultProblemOf(p); dp.Detail != "verbose" {
+		t.Errorf("expect = %v, actual = %v", "verbose", dp.Detail)
+	}
+}
+
+func TestRedaction_Builder(t *testing.T) {
+	p := New().InternalServerError("verbose")
+	if dp := defaultProblemOf(p); dp.Detail != "verbose" {
+		t.Errorf("expect = %v, actual = %v", "verbose", dp.Detail)
+	}
+	p = New().Mode(Production).InternalServerError("verbose")
+	if dp := defaultProblemOf(p); dp.Detail != RedactedDetail {
+		t.Errorf("expect = %v, actual = %v", RedactedDetail, dp.Detail)
+	}
+}
+
+func TestRedaction_Reference(t *testing.T) {
+	l := &captureLogger{}
+	SetLogger(l)
+	defer SetLogger(ZerologLogger)
+
+	p := New().Mode(Production).Reference(true).InternalServerError("verbose")
+	ref := defaultProblemOf(p).Extensions()[ReferenceKey]
+	if len(l.entries) != 1 || l.entries[0].Message != "verbose" || l.entries[0].Fields[ReferenceKey] != ref {
+		t.Errorf("expect = %v, actual = %v", "verbose", l.entries)
+	}
+
+	SetMode(Production)
+	defer SetMode(Development)
+	SetReference(true)
+	defer SetReference(false)
+	l.entries = nil
+	err := errors.New("secret")
+	p = Of(context.TODO(), "/users", WrapError(err))
+	dp := defaultProblemOf(p)
+	if dp.Detail != RedactedDetail || len(l.entries) != 1 {
+		t.Fatalf("expect = %v, actual = %v", 1, len(l.entries))
+	}
+	if e := l.entries[0]; e.Err != err || e.Message != "secret" || e.Fields[ReferenceKey] != dp.Extensions()[ReferenceKey] {
+		t.Errorf("expect = %v, actual = %v", err, e)
+	}
+}
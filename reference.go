@@ -0,0 +1,117 @@
+package problems
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	ReferenceKey       = "reference"
+	TraceparentHeader  = "traceparent"
+	zeroTraceID        = "00000000000000000000000000000000"
+	traceparentVersion = "00"
+)
+
+type referenceKey struct{}
+
+var referenceEnabled atomic.Bool
+
+// SetReference enables reference generation for Of and for builders without their own setting.
+func SetReference(enabled bool) {
+	referenceEnabled.Store(enabled)
+}
+
+// WithReference returns a context carrying the reference used by Of when it attaches one.
+func WithReference(ctx context.Context, ref string) context.Context {
+	return context.WithValue(ctx, referenceKey{}, ref)
+}
+
+// ReferenceFrom returns the reference carried by the context, or an empty string.
+func ReferenceFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ref, _ := ctx.Value(referenceKey{}).(string)
+	return ref
+}
+
+// TraceID returns the trace-id of a W3C traceparent header value, or an empty string if it is invalid.
+func TraceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	if parts[0] == traceparentVersion && len(parts) != 4 {
+		return ""
+	}
+	traceID := parts[1]
+	if traceID == zeroTraceID || !isLowerHex(traceID) || !isLowerHex(parts[2]) {
+		return ""
+	}
+	return traceID
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestContext returns the context of the request, carrying the trace-id of its traceparent header as the reference.
+func requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if ReferenceFrom(ctx) != "" {
+		return ctx
+	}
+	if id := TraceID(r.Header.Get(TraceparentHeader)); id != "" {
+		return WithReference(ctx, id)
+	}
+	return ctx
+}
+
+// Reference sets the reference extension member. An empty id generates a new reference.
+func Reference(id string) Option {
+	return func(p DefaultParams) Problem {
+		ref := id
+		if ref == "" {
+			ref = newReference()
+		}
+		return Extension(ReferenceKey, ref)(p)
+	}
+}
+
+func newReference() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+func referenceOf(p Problem) string {
+	if dp := defaultProblemOf(p); dp != nil {
+		ref, _ := dp.Extensions()[ReferenceKey].(string)
+		return ref
+	}
+	return ""
+}
+
+// setReference sets ref unless the problem already has a reference, and returns the reference of the problem.
+func setReference(p Problem, ref string) string {
+	dp := defaultProblemOf(p)
+	if dp == nil {
+		return ""
+	}
+	if v := referenceOf(p); v != "" {
+		return v
+	}
+	dp.SetExtension(ReferenceKey, ref)
+	return ref
+}
@@ -0,0 +1,100 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceID(t *testing.T) {
+	tests := map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":    "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":    "",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":    "",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xx": "",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xx": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":    "",
+		"invalid": "",
+	}
+	for v, expect := range tests {
+		if actual := TraceID(v); actual != expect {
+			t.Errorf("expect = %v, actual = %v", expect, actual)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	p := Of(context.TODO(), "/users", errors.New("failure"))
+	if ref := referenceOf(p); ref != "" {
+		t.Errorf("expect = empty, actual = %v", ref)
+	}
+
+	SetReference(true)
+	defer SetReference(false)
+	p = Of(WithReference(context.TODO(), "abc"), "/users", errors.New("failure"))
+	if ref := referenceOf(p); ref != "abc" {
+		t.Errorf("expect = %v, actual = %v", "abc", ref)
+	}
+	p = Of(context.TODO(), "/users", errors.New("failure"))
+	if ref := referenceOf(p); len(ref) != 32 {
+		t.Errorf("expect = generated reference, actual = %v", ref)
+	}
+	p = New().Reference(false).NotFound("not found")
+	if ref := referenceOf(p); ref != "" {
+		t.Errorf("expect = empty, actual = %v", ref)
+	}
+	p = New(Reference("xyz")).NotFound("not found")
+	if ref := referenceOf(p); ref != "xyz" {
+		t.Errorf("expect = %v, actual = %v", "xyz", ref)
+	}
+}
+
+func TestReference_Builder(t *testing.T) {
+	p := New().Reference(true).BadRequest("invalid")
+	if ref := referenceOf(p); len(ref) != 32 {
+		t.Errorf("expect = generated reference, actual = %v", ref)
+	}
+	opt := Reference("")
+	if a, b := referenceOf(New(opt).Conflict("")), referenceOf(New(opt).Conflict("")); a == "" || a == b {
+		t.Errorf("expect = unique references, actual = %v, %v", a, b)
+	}
+}
+
+func TestReference_Traceparent(t *testing.T) {
+	SetReference(true)
+	defer SetReference(false)
+	h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("failure")
+	})
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	r.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	p, err := Decode(context.TODO(), w.Code, w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := referenceOf(p); ref != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expect = %v, actual = %v", "4bf92f3577b34da6a3ce929d0e0e4736", ref)
+	}
+}
+
+func TestReference_Status(t *testing.T) {
+	SetMode(Production)
+	defer SetMode(Development)
+
+	p := Of(context.TODO(), "/users", errors.New("connection refused"))
+	ref := referenceOf(p)
+	if ref == "" {
+		t.Fatalf("expect = reference, actual = %v", p)
+	}
+	restored := FromStatus(ToStatus(p))
+	if actual := referenceOf(restored); actual != ref {
+		t.Errorf("expect = %v, actual = %v", ref, actual)
+	}
+	if actual := referenceOf(Of(context.TODO(), "/users", ToStatus(p).Err())); actual != ref {
+		t.Errorf("expect = %v, actual = %v", ref, actual)
+	}
+}
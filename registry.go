@@ -0,0 +1,141 @@
+package problems
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ProblemType describes a problem type registered against its type URI.
+type ProblemType struct {
+	Type   string
+	Status int
+	Title  string
+	New    func() Problem
+}
+
+// TypeOption configures defaults of a registered problem type.
+type TypeOption func(t *ProblemType)
+
+// DefaultStatus sets the status applied when a decoded problem does not contain one.
+func DefaultStatus(status int) TypeOption {
+	return func(t *ProblemType) {
+		t.Status = status
+	}
+}
+
+// DefaultTitle sets the title applied when a decoded problem does not contain one.
+func DefaultTitle(title string) TypeOption {
+	return func(t *ProblemType) {
+		t.Title = title
+	}
+}
+
+type StatusParameter interface {
+	SetStatus(status int)
+}
+
+// Registry maps problem type URIs to the Go types used when decoding them.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]ProblemType
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]ProblemType)}
+}
+
+// DefaultRegistry is consulted by Bind, Decode and GraphQLError.Problem.
+var DefaultRegistry = NewRegistry()
+
+// Register registers the constructor f for the type URI.
+// f must return a pointer whose embedded structs are initialized.
+func (r *Registry) Register(uri string, f func() Problem, opts ...TypeOption) error {
+	if uri == "" || uri == DefaultType {
+		return fmt.Errorf("problems: invalid type uri '%s'", uri)
+	}
+	if f == nil {
+		return errors.New("problems: constructor is nil")
+	}
+	t := ProblemType{Type: uri, New: f}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[uri]; ok {
+		return fmt.Errorf("problems: type '%s' is already registered", uri)
+	}
+	r.types[uri] = t
+	return nil
+}
+
+// Lookup returns the problem type registered for the type URI.
+func (r *Registry) Lookup(uri string) (ProblemType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[uri]
+	return t, ok
+}
+
+// Types returns the registered problem types sorted by type URI.
+func (r *Registry) Types() []ProblemType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]ProblemType, 0, len(r.types))
+	for _, t := range r.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// Create instantiates the problem registered for the type URI with its defaults applied.
+func (r *Registry) Create(uri string) Problem {
+	t, ok := r.Lookup(uri)
+	if !ok {
+		return nil
+	}
+	p := t.New()
+	if p == nil {
+		return nil
+	}
+	if dp, ok := p.(DefaultParams); ok {
+		dp.SetType(t.Type)
+		if t.Title != "" {
+			dp.SetTitle(t.Title)
+		}
+	}
+	if sp, ok := p.(StatusParameter); ok && t.Status > 0 {
+		sp.SetStatus(t.Status)
+	}
+	return p
+}
+
+func Register(uri string, f func() Problem, opts ...TypeOption) error {
+	return DefaultRegistry.Register(uri, f, opts...)
+}
+
+func peekType(body []byte) string {
+	if isXML(body) {
+		root, err := parseXML(body)
+		if err != nil {
+			return ""
+		}
+		if n := root.child("type"); n != nil {
+			return n.value()
+		}
+		return ""
+	}
+	var v struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return ""
+	}
+	return v.Type
+}
@@ -0,0 +1,128 @@
+package problems
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type OutOfCredit struct {
+	*DefaultProblem
+	Balance  int      `json:"balance"`
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+const outOfCreditType = "https://example.com/probs/out-of-credit"
+
+// outOfCreditRegistry returns a registry of OutOfCredit, leaving DefaultRegistry unchanged.
+func outOfCreditRegistry() *Registry {
+	r := NewRegistry()
+	if err := r.Register(outOfCreditType, func() Problem {
+		return &OutOfCredit{DefaultProblem: &DefaultProblem{}}
+	}, DefaultStatus(http.StatusForbidden), DefaultTitle("You do not have enough credit.")); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// useOutOfCreditRegistry replaces DefaultRegistry with outOfCreditRegistry until the returned function is called.
+func useOutOfCreditRegistry() func() {
+	r := DefaultRegistry
+	DefaultRegistry = outOfCreditRegistry()
+	return func() { DefaultRegistry = r }
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	f := func() Problem { return &DefaultProblem{} }
+	if err := r.Register(DefaultType, f); err == nil {
+		t.Errorf("expect = error, actual = nil")
+	}
+	if err := r.Register("https://example.com/a", f); err != nil {
+		t.Errorf("%v", err)
+	}
+	if err := r.Register("https://example.com/a", f); err == nil {
+		t.Errorf("expect = error, actual = nil")
+	}
+	if types := r.Types(); len(types) != 1 || types[0].Type != "https://example.com/a" {
+		t.Errorf("expect = https://example.com/a, actual = %v", types)
+	}
+}
+
+func TestBind_RegisteredType(t *testing.T) {
+	defer useOutOfCreditRegistry()()
+	body := `{"type":"` + outOfCreditType + `","detail":"Your current balance is 30","balance":30,"accounts":["/account/12345"]}`
+	p, err := Bind(context.TODO(), http.StatusForbidden, []byte(body))
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	oc, ok := p.(*OutOfCredit)
+	if !ok {
+		t.Fatalf("expect = OutOfCredit, actual = %v", p)
+	}
+	if oc.Balance != 30 {
+		t.Errorf("expect = 30, actual = %d", oc.Balance)
+	}
+	if oc.Status != http.StatusForbidden {
+		t.Errorf("expect = %d, actual = %d", http.StatusForbidden, oc.Status)
+	}
+	if oc.Title != "You do not have enough credit." {
+		t.Errorf("expect = You do not have enough credit., actual = %s", oc.Title)
+	}
+	if len(oc.Extensions()) != 0 {
+		t.Errorf("expect = no extensions, actual = %v", oc.Extensions())
+	}
+}
+
+func TestDecode_RegisteredType(t *testing.T) {
+	defer useOutOfCreditRegistry()()
+	body := `{"type":"` + outOfCreditType + `","title":"Out of credit","status":402,"balance":10}`
+	p, err := Decode(context.TODO(), http.StatusPaymentRequired, strings.NewReader(body))
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if oc, ok := p.(*OutOfCredit); !ok {
+		t.Errorf("expect = OutOfCredit, actual = %v", p)
+	} else if oc.Title != "Out of credit" || oc.Status != http.StatusPaymentRequired {
+		t.Errorf("expect = Out of credit/402, actual = %s/%d", oc.Title, oc.Status)
+	}
+	p, err = Decode(context.TODO(), http.StatusNotFound, strings.NewReader(`{"type":"https://example.com/unknown"}`))
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if _, ok := p.(*DefaultProblem); !ok {
+		t.Errorf("expect = DefaultProblem, actual = %v", p)
+	}
+}
+
+func TestGraphQLError_RegisteredType(t *testing.T) {
+	defer useOutOfCreditRegistry()()
+	err := &GraphQLError{
+		Message:    "Your current balance is 30",
+		Extensions: map[string]interface{}{"type": outOfCreditType, "status": http.StatusForbidden},
+	}
+	if _, ok := err.Problem().(*OutOfCredit); !ok {
+		t.Errorf("expect = OutOfCredit, actual = %v", err.Problem())
+	}
+}
+
+func TestDecode_ExplicitFactory(t *testing.T) {
+	defer useOutOfCreditRegistry()()
+	body := `{"type":"` + outOfCreditType + `","detail":"Your current balance is 30","balance":30}`
+	f := func(status int) Problem { return &BadRequest{DefaultProblem: &DefaultProblem{}} }
+	p, err := Decode(context.TODO(), http.StatusForbidden, strings.NewReader(body), f)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if _, ok := p.(*BadRequest); !ok {
+		t.Errorf("expect = BadRequest, actual = %T", p)
+	}
+	p, err = Bind(context.TODO(), http.StatusForbidden, []byte(body), func(int) Problem { return nil })
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if _, ok := p.(*OutOfCredit); !ok {
+		t.Errorf("expect = OutOfCredit, actual = %T", p)
+	}
+}
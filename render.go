@@ -0,0 +1,204 @@
+package problems
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+const (
+	GraphQLResponseJson = "application/graphql-response+json"
+)
+
+type representation int
+
+const (
+	jsonRepresentation representation = iota
+	xmlRepresentation
+	graphqlRepresentation
+)
+
+var representations = []struct {
+	mediaType string
+	repr      representation
+}{
+	{mimetypes.ProblemJson, jsonRepresentation},
+	{mimetypes.ProblemXml, xmlRepresentation},
+	{GraphQLResponseJson, graphqlRepresentation},
+	{mimetypes.JSON, jsonRepresentation},
+	{mimetypes.XML, xmlRepresentation},
+	{"text/xml", xmlRepresentation},
+}
+
+// Negotiator selects the problem representation from the Accept header of a request.
+type Negotiator struct {
+	// Fallback is the media type used when the request expresses no preference.
+	Fallback string
+}
+
+var DefaultNegotiator = &Negotiator{Fallback: mimetypes.ProblemJson}
+
+// Render writes the problem in the representation negotiated by DefaultNegotiator.
+func Render(ctx context.Context, w http.ResponseWriter, r *http.Request, problem Problem) {
+	DefaultNegotiator.Render(ctx, w, r, problem)
+}
+
+// Render writes the problem in the representation acceptable to the request,
+// or a 406 problem when no representation is acceptable.
+func (n *Negotiator) Render(ctx context.Context, w http.ResponseWriter, r *http.Request, problem Problem) {
+	w.Header().Add(headers.Vary, headers.Accept)
+	accept := ""
+	if r != nil {
+		accept = r.Header.Get(headers.Accept)
+	}
+	mediaType, ok := n.Negotiate(accept)
+	if !ok {
+		problem = New(Path(r)).NotAcceptable("Acceptable media types are %s", strings.Join(n.mediaTypes(), ", "))
+		mediaType = n.fallback()
+	}
+	write(ctx, w, mediaType, problem)
+}
+
+// Negotiate returns the supported media type with the highest quality in the Accept header.
+func (n *Negotiator) Negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return n.fallback(), true
+	}
+	ranges := parseAccept(accept)
+	selected, quality, specificity := "", 0.0, -1
+	for _, mediaType := range n.mediaTypes() {
+		q, s := matchQuality(ranges, mediaType)
+		if q > quality || (q > 0 && q == quality && s > specificity) {
+			selected, quality, specificity = mediaType, q, s
+		}
+	}
+	return selected, selected != ""
+}
+
+func (n *Negotiator) fallback() string {
+	if n != nil && n.Fallback != "" {
+		if _, ok := lookupRepresentation(n.Fallback); ok {
+			return n.Fallback
+		}
+	}
+	return mimetypes.ProblemJson
+}
+
+// mediaTypes returns the supported media types, preferring the fallback on equal quality.
+func (n *Negotiator) mediaTypes() []string {
+	fallback := n.fallback()
+	list := make([]string, 0, len(representations))
+	list = append(list, fallback)
+	for _, r := range representations {
+		if r.mediaType != fallback {
+			list = append(list, r.mediaType)
+		}
+	}
+	return list
+}
+
+func lookupRepresentation(mediaType string) (representation, bool) {
+	for _, r := range representations {
+		if r.mediaType == mediaType {
+			return r.repr, true
+		}
+	}
+	return jsonRepresentation, false
+}
+
+func write(ctx context.Context, w http.ResponseWriter, mediaType string, problem Problem) {
+	repr, _ := lookupRepresentation(mediaType)
+	if mediaType != mimetypes.ProblemJson && mediaType != mimetypes.ProblemXml {
+		w = &mediaTypeWriter{ResponseWriter: w, mediaType: mediaType}
+	}
+	switch repr {
+	case xmlRepresentation:
+		problem.XML(ctx, w)
+	case graphqlRepresentation:
+		if gr, ok := problem.(GraphQLRenderer); ok {
+			gr.GraphQL(ctx, w)
+		} else {
+			WriteGraphQL(ctx, w, problem.ProblemStatus(), problem)
+		}
+	default:
+		problem.JSON(ctx, w)
+	}
+}
+
+// mediaTypeWriter replaces the Content-Type set by the renderers with the negotiated media type.
+type mediaTypeWriter struct {
+	http.ResponseWriter
+	mediaType   string
+	wroteHeader bool
+}
+
+func (w *mediaTypeWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set(headers.ContentType, w.mediaType)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+func (w *mediaTypeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+func (w *mediaTypeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type mediaRange struct {
+	typ     string
+	subtype string
+	quality float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, quality: q})
+	}
+	return ranges
+}
+
+// matchQuality returns the quality and specificity of the most specific range matching mediaType.
+func matchQuality(ranges []mediaRange, mediaType string) (float64, int) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality, specificity
+}
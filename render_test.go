@@ -0,0 +1,71 @@
+package problems
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccha/http-constants/pkg/headers"
+	"github.com/goccha/http-constants/pkg/mimetypes"
+)
+
+func TestNegotiator_Negotiate(t *testing.T) {
+	n := &Negotiator{Fallback: mimetypes.ProblemJson}
+	tests := []struct {
+		accept string
+		expect string
+		ok     bool
+	}{
+		{"", mimetypes.ProblemJson, true},
+		{"*/*", mimetypes.ProblemJson, true},
+		{"application/problem+xml", mimetypes.ProblemXml, true},
+		{"application/problem+xml, */*", mimetypes.ProblemXml, true},
+		{"application/problem+json;q=0.5, application/problem+xml;q=0.8", mimetypes.ProblemXml, true},
+		{"application/xml;q=0.9, application/*;q=0.1", mimetypes.XML, true},
+		{"application/graphql-response+json", GraphQLResponseJson, true},
+		{"text/html, */*;q=0.1", mimetypes.ProblemJson, true},
+		{"application/problem+json;q=0, */*;q=0.5", mimetypes.ProblemXml, true},
+		{"text/html", "", false},
+	}
+	for _, test := range tests {
+		actual, ok := n.Negotiate(test.accept)
+		if actual != test.expect || ok != test.ok {
+			t.Errorf("%s: expect = %s(%v), actual = %s(%v)", test.accept, test.expect, test.ok, actual, ok)
+		}
+	}
+	n = &Negotiator{Fallback: mimetypes.ProblemXml}
+	if actual, _ := n.Negotiate("text/html, */*;q=0.1"); actual != mimetypes.ProblemXml {
+		t.Errorf("expect = %s, actual = %s", mimetypes.ProblemXml, actual)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		status      int
+	}{
+		{"", mimetypes.ProblemJson, http.StatusNotFound},
+		{"application/problem+xml", mimetypes.ProblemXml, http.StatusNotFound},
+		{"application/graphql-response+json", GraphQLResponseJson, http.StatusNotFound},
+		{"application/json", mimetypes.JSON, http.StatusNotFound},
+		{"application/xml", mimetypes.XML, http.StatusNotFound},
+		{"text/xml;q=0.9, text/html", "text/xml", http.StatusNotFound},
+		{"text/html", mimetypes.ProblemJson, http.StatusNotAcceptable},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if test.accept != "" {
+			req.Header.Set(headers.Accept, test.accept)
+		}
+		w := httptest.NewRecorder()
+		Render(context.TODO(), w, req, New(Path(req)).NotFound("user not found"))
+		if actual := w.Header().Get(headers.ContentType); actual != test.contentType {
+			t.Errorf("%s: expect = %s, actual = %s", test.accept, test.contentType, actual)
+		}
+		if w.Code != test.status {
+			t.Errorf("%s: expect = %d, actual = %d", test.accept, test.status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package problems
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	RetryableKey = "retryable"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Retryable reports whether the request that produced the problem may be retried.
+// A boolean retryable extension member takes precedence over the status (429, 503 and 504).
+func Retryable(p Problem) bool {
+	if p == nil {
+		return false
+	}
+	if dp := defaultProblemOf(p); dp != nil {
+		if v, ok := dp.Extensions()[RetryableKey].(bool); ok {
+			return v
+		}
+	}
+	switch p.ProblemStatus() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryClient retries requests whose response is a retryable problem.
+type RetryClient struct {
+	// Client sends the requests; http.DefaultClient is used if nil.
+	Client *http.Client
+	// MaxRetries is the number of retries; DefaultMaxRetries is used if zero, no retries if negative.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the jittered exponential backoff used without Retry-After.
+	// MaxBackoff also caps the delay requested by the server.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxBodySize limits the problem body; DefaultMaxBodySize is used if zero or less.
+	MaxBodySize int64
+}
+
+// Do sends the request, retrying while the response is a retryable problem.
+// Requests with a body are retried only if GetBody is set.
+// The last ProblemError is returned when the retries are exhausted.
+func (c *RetryClient) Do(req *http.Request) (*http.Response, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		res, err := client.Do(r)
+		if err == nil {
+			err = checkResponse(ctx, res, c.MaxBodySize)
+		}
+		pe := &ProblemError{}
+		if err == nil || !errors.As(err, &pe) {
+			return res, err
+		}
+		if attempt >= c.maxRetries() || !Retryable(pe.Problem()) || !replayable(req) {
+			return nil, pe
+		}
+		timer := time.NewTimer(c.delay(pe, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *RetryClient) maxRetries() int {
+	if c.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// delay returns the Retry-After header, the retry-after extension member, or a jittered backoff, at most MaxBackoff.
+func (c *RetryClient) delay(pe *ProblemError, attempt int) time.Duration {
+	min, max := c.MinBackoff, c.MaxBackoff
+	if min <= 0 {
+		min = DefaultMinBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	if dp := defaultProblemOf(pe.Problem()); dp != nil {
+		d, ok := dp.RetryAfter()
+		if !ok {
+			d, ok = retryDelay(dp.Extensions()[RetryAfterKey])
+		}
+		if ok {
+			if d > max {
+				return max
+			}
+			return d
+		}
+	}
+	backoff := max
+	if attempt < 32 && min<<attempt > 0 && min<<attempt < max {
+		backoff = min << attempt
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
@@ -0,0 +1,139 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goccha/http-constants/pkg/headers"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		p      Problem
+		expect bool
+	}{
+		{p: New().TooManyRequests(""), expect: true},
+		{p: New().Unavailable(""), expect: true},
+		{p: New().GatewayTimeout(""), expect: true},
+		{p: New().InternalServerError(""), expect: false},
+		{p: New(Extension(RetryableKey, true)).Conflict(""), expect: true},
+		{p: New(Extension(RetryableKey, false)).Unavailable(""), expect: false},
+		{p: nil, expect: false},
+	}
+	for _, tt := range tests {
+		if actual := Retryable(tt.p); actual != tt.expect {
+			t.Errorf("expect = %v, actual = %v", tt.expect, actual)
+		}
+	}
+}
+
+func TestRetryClient(t *testing.T) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			New().BadRequest("body").JSON(r.Context(), w)
+			return
+		}
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.Header().Set(headers.RetryAfter, "0")
+			New().Unavailable("maintenance").JSON(r.Context(), w)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	res, err := (&RetryClient{}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || atomic.LoadInt32(&count) != 3 {
+		t.Errorf("expect = 200 3, actual = %d %d", res.StatusCode, count)
+	}
+}
+
+func TestRetryClient_Exhausted(t *testing.T) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		New(Extension(RetryAfterKey, 0)).TooManyRequests("slow down").JSON(r.Context(), w)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, err := (&RetryClient{MaxRetries: 2}).Do(req)
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) || pe.Problem().ProblemStatus() != http.StatusTooManyRequests {
+		t.Errorf("expect = %v, actual = %v", http.StatusTooManyRequests, err)
+	}
+	if atomic.LoadInt32(&count) != 3 {
+		t.Errorf("expect = %v, actual = %v", 3, count)
+	}
+}
+
+func TestRetryClient_NotRetryable(t *testing.T) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		New().NotFound("user not found").JSON(r.Context(), w)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, err := (&RetryClient{}).Do(req)
+	pe := &ProblemError{}
+	if !errors.As(err, &pe) || atomic.LoadInt32(&count) != 1 {
+		t.Errorf("expect = 1, actual = %d %v", count, err)
+	}
+}
+
+func TestRetryClient_Context(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.RetryAfter, "60")
+		New().Unavailable("maintenance").JSON(r.Context(), w)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	_, err := (&RetryClient{}).Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect = %v, actual = %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRetryClient_Backoff(t *testing.T) {
+	c := &RetryClient{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	pe := &ProblemError{problem: New().Unavailable("")}
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if d := c.delay(pe, attempt); d < max/2 || d > max {
+			t.Errorf("expect = %v..%v, actual = %v", max/2, max, d)
+		}
+	}
+}
+
+func TestRetryClient_MaxRetryAfter(t *testing.T) {
+	c := &RetryClient{MaxBackoff: 4 * time.Second}
+	tests := []Problem{
+		New(RetryAfter(time.Hour)).Unavailable(""),
+		New(Extension(RetryAfterKey, 3600)).Unavailable(""),
+	}
+	for _, p := range tests {
+		if d := c.delay(&ProblemError{problem: p}, 0); d != 4*time.Second {
+			t.Errorf("expect = %v, actual = %v", 4*time.Second, d)
+		}
+	}
+	if d := c.delay(&ProblemError{problem: New(RetryAfter(time.Second)).Unavailable("")}, 0); d != time.Second {
+		t.Errorf("expect = %v, actual = %v", time.Second, d)
+	}
+}
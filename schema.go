@@ -0,0 +1,358 @@
+package problems
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema 2020-12 subset describing problem documents, also used by OpenAPI 3.1.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	XML                  *XMLObject         `json:"xml,omitempty"`
+}
+
+// XMLObject is the OpenAPI XML Object describing the problem+xml representation.
+type XMLObject struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Wrapped   bool   `json:"wrapped,omitempty"`
+}
+
+const (
+	problemSchema         = "Problem"
+	badRequestSchema      = "BadRequest"
+	invalidParamSchema    = "InvalidParam"
+	validationErrorSchema = "ValidationError"
+)
+
+var (
+	defaultProblemType  = reflect.TypeOf(DefaultProblem{})
+	codeProblemType     = reflect.TypeOf(CodeProblem{})
+	badRequestType      = reflect.TypeOf(BadRequest{})
+	invalidParamType    = reflect.TypeOf(InvalidParam{})
+	validationErrorType = reflect.TypeOf(ValidationError{})
+	timeType            = reflect.TypeOf(time.Time{})
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaGenerator reflects problem types into schemas referring to the built-in definitions by prefix.
+type schemaGenerator struct {
+	prefix string
+	// types being reflected, to stop at recursive types
+	visiting map[reflect.Type]bool
+}
+
+func newSchemaGenerator(prefix string) *schemaGenerator {
+	return &schemaGenerator{prefix: prefix, visiting: make(map[reflect.Type]bool)}
+}
+
+func (g *schemaGenerator) ref(name string) *Schema {
+	return &Schema{Ref: g.prefix + name}
+}
+
+// builtins returns the schemas of DefaultProblem, BadRequest, InvalidParam and ValidationError by name.
+func (g *schemaGenerator) builtins() map[string]*Schema {
+	status := &Schema{Type: "integer", Description: "The HTTP status code.", Minimum: intPtr(100), Maximum: intPtr(599)}
+	return map[string]*Schema{
+		problemSchema: {
+			Type:        "object",
+			Description: "Problem details for HTTP APIs (RFC 9457).",
+			Properties: map[string]*Schema{
+				"type":     {Type: "string", Format: "uri-reference", Default: DefaultType, Description: "A URI reference that identifies the problem type."},
+				"title":    {Type: "string", Description: "A short, human-readable summary of the problem type."},
+				"status":   status,
+				"detail":   {Type: "string", Description: "A human-readable explanation specific to this occurrence of the problem."},
+				"instance": {Type: "string", Format: "uri-reference", Description: "A URI reference that identifies the specific occurrence of the problem."},
+				"code":     {Type: "string", Description: "An application specific problem code."},
+			},
+			XML: &XMLObject{Name: "problem", Namespace: XmlNamespace},
+		},
+		badRequestSchema: {
+			AllOf: []*Schema{g.ref(problemSchema), {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"invalid-params": xmlArray(g.ref(invalidParamSchema)),
+					"errors":         xmlArray(g.ref(validationErrorSchema)),
+				},
+			}},
+			XML: &XMLObject{Name: "problem", Namespace: XmlNamespace},
+		},
+		invalidParamSchema:    g.object(invalidParamType),
+		validationErrorSchema: g.object(validationErrorType),
+	}
+}
+
+// definitions returns the built-in schemas and the schemas of the types registered in r by name.
+func (g *schemaGenerator) definitions(r *Registry) (map[string]*Schema, []namedType) {
+	defs := g.builtins()
+	types := namedTypes(r.Types(), defs)
+	for _, t := range types {
+		defs[t.name] = t.schema(g)
+	}
+	return defs, types
+}
+
+type namedType struct {
+	name string
+	t    ProblemType
+}
+
+// namedTypes names the problem types after their type URIs, avoiding the names already used.
+func namedTypes(types []ProblemType, used map[string]*Schema) []namedType {
+	names := make(map[string]bool, len(used)+len(types))
+	for name := range used {
+		names[name] = true
+	}
+	named := make([]namedType, 0, len(types))
+	for _, t := range types {
+		base := typeName(t.Type)
+		name := base
+		for i := 2; names[name]; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		names[name] = true
+		named = append(named, namedType{name: name, t: t})
+	}
+	return named
+}
+
+// schema returns the schema of the registered type, fixing its type URI and default status.
+func (n namedType) schema(g *schemaGenerator) *Schema {
+	members := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type": {Type: "string", Const: n.t.Type},
+		},
+	}
+	if n.t.Status > 0 {
+		members.Properties["status"] = &Schema{Type: "integer", Default: n.t.Status}
+	}
+	if n.t.Title != "" {
+		members.Properties["title"] = &Schema{Type: "string", Examples: []interface{}{n.t.Title}}
+	}
+	var s *Schema
+	if n.t.New != nil {
+		if p := n.t.New(); p != nil {
+			s = g.problem(reflect.TypeOf(p))
+		}
+	}
+	if s == nil {
+		s = g.ref(problemSchema)
+	}
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s, members}}
+	}
+	if s.AllOf != nil {
+		s.AllOf = append(s.AllOf, members)
+		return s
+	}
+	for name, p := range members.Properties {
+		if _, ok := s.Properties[name]; !ok {
+			s.Properties[name] = p
+		}
+	}
+	return s
+}
+
+// problem returns the schema of a problem type, extending the built-in schema it embeds.
+func (g *schemaGenerator) problem(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case defaultProblemType, codeProblemType:
+		return g.ref(problemSchema)
+	case badRequestType:
+		return g.ref(badRequestSchema)
+	}
+	s := &Schema{Type: "object"}
+	if t.Kind() != reflect.Struct {
+		return s
+	}
+	base := ""
+	g.fields(t, s, &base)
+	if base == "" {
+		s.XML = &XMLObject{Name: "problem", Namespace: XmlNamespace}
+		return s
+	}
+	if len(s.Properties) == 0 {
+		return g.ref(base)
+	}
+	return &Schema{AllOf: []*Schema{g.ref(base), s}}
+}
+
+// fields adds the JSON members of the struct t to s, flattening embedded structs.
+// An embedded built-in problem is recorded in base instead.
+func (g *schemaGenerator) fields(t reflect.Type, s *Schema, base *string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		name, omitempty, ok := jsonField(f)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			switch ft {
+			case defaultProblemType, codeProblemType:
+				if *base == "" {
+					*base = problemSchema
+				}
+				continue
+			case badRequestType:
+				*base = badRequestSchema
+				continue
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s, base)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if s.Properties == nil {
+			s.Properties = make(map[string]*Schema)
+		}
+		s.Properties[name] = g.reflect(f.Type)
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func jsonField(f reflect.StructField) (name string, omitempty bool, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	if !f.IsExported() && !(f.Anonymous && name == "") {
+		return "", false, false
+	}
+	return name, omitempty, true
+}
+
+// reflect returns the schema of a Go type as encoded by encoding/json.
+func (g *schemaGenerator) reflect(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case invalidParamType:
+		return g.ref(invalidParamSchema)
+	case validationErrorType:
+		return g.ref(validationErrorSchema)
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return xmlArray(g.reflect(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.reflect(t.Elem())}
+	case reflect.Struct:
+		return g.object(t)
+	}
+	return &Schema{}
+}
+
+// object returns the schema of the struct t, without the members of recursive types.
+func (g *schemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object"}
+	if g.visiting[t] {
+		return s
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+	base := ""
+	g.fields(t, s, &base)
+	return s
+}
+
+// xmlArray returns an array schema whose XML items are <i> elements.
+func xmlArray(items *Schema) *Schema {
+	if items.XML == nil {
+		items.XML = &XMLObject{Name: "i"}
+	}
+	return &Schema{Type: "array", Items: items, XML: &XMLObject{Wrapped: true}}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// typeName converts the last segment of a type URI such as https://example.com/probs/out-of-credit into OutOfCredit.
+func typeName(uri string) string {
+	if u, err := url.Parse(uri); err == nil {
+		if u.Opaque != "" {
+			uri = u.Opaque
+		} else if u.Path != "" {
+			uri = u.Path
+		}
+	}
+	uri = strings.TrimRight(uri, "/")
+	if i := strings.LastIndexAny(uri, "/:#"); i >= 0 {
+		uri = uri[i+1:]
+	}
+	if i := strings.IndexByte(uri, '.'); i > 0 {
+		uri = uri[:i]
+	}
+	buf := strings.Builder{}
+	for _, part := range strings.FieldsFunc(uri, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	name := buf.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = problemSchema + name
+	}
+	return name
+}
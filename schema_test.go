@@ -0,0 +1,58 @@
+package problems
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaNode struct {
+	Name     string        `json:"name"`
+	At       time.Time     `json:"at,omitempty"`
+	Children []*schemaNode `json:"children,omitempty"`
+	Labels   map[string]int
+	Ignored  string `json:"-"`
+	hidden   string
+}
+
+func TestSchemaGenerator_Reflect(t *testing.T) {
+	g := newSchemaGenerator("#/$defs/")
+	s := g.reflect(reflect.TypeOf(&schemaNode{}))
+	if s.Type != "object" || len(s.Properties) != 4 {
+		t.Fatalf("expect = %v, actual = %v", 4, s.Properties)
+	}
+	if expect := []string{"name", "Labels"}; !reflect.DeepEqual(s.Required, expect) {
+		t.Errorf("expect = %v, actual = %v", expect, s.Required)
+	}
+	if at := s.Properties["at"]; at.Type != "string" || at.Format != "date-time" {
+		t.Errorf("expect = %v, actual = %v", "date-time", at)
+	}
+	if labels := s.Properties["Labels"]; labels.Type != "object" || labels.AdditionalProperties.Type != "integer" {
+		t.Errorf("expect = %v, actual = %v", "map of integer", labels)
+	}
+	children := s.Properties["children"]
+	if children.Type != "array" || children.Items.Type != "object" || children.Items.Properties != nil {
+		t.Errorf("expect = %v, actual = %v", "array of object", children.Items)
+	}
+	if s := g.reflect(reflect.TypeOf([]InvalidParam{})); s.Items.Ref != "#/$defs/InvalidParam" || s.Items.XML.Name != "i" {
+		t.Errorf("expect = %v, actual = %v", "#/$defs/InvalidParam", s.Items)
+	}
+}
+
+func TestSchemaGenerator_Problem(t *testing.T) {
+	g := newSchemaGenerator("#/$defs/")
+	if s := g.problem(reflect.TypeOf(&CodeProblem{})); s.Ref != "#/$defs/Problem" {
+		t.Errorf("expect = %v, actual = %v", "#/$defs/Problem", s.Ref)
+	}
+	s := g.problem(reflect.TypeOf(&OutOfCredit{}))
+	if len(s.AllOf) != 2 || s.AllOf[0].Ref != "#/$defs/Problem" {
+		t.Fatalf("expect = %v, actual = %v", "#/$defs/Problem", s.AllOf)
+	}
+	members := s.AllOf[1]
+	if members.Properties["balance"].Type != "integer" || members.Properties["accounts"].Type != "array" {
+		t.Errorf("expect = %v, actual = %v", "balance and accounts", members.Properties)
+	}
+	if expect := []string{"balance"}; !reflect.DeepEqual(members.Required, expect) {
+		t.Errorf("expect = %v, actual = %v", expect, members.Required)
+	}
+}
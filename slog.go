@@ -0,0 +1,126 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// LogValue implements slog.LogValuer.
+func (p *DefaultProblem) LogValue() slog.Value {
+	return slog.GroupValue(p.logAttrs()...)
+}
+
+func (p *DefaultProblem) logAttrs() []slog.Attr {
+	if p == nil {
+		return nil
+	}
+	attrs := []slog.Attr{
+		slog.String("type", p.Type),
+		slog.String("title", p.Title),
+		slog.Int("status", p.Status),
+	}
+	if p.Code != "" {
+		attrs = append(attrs, slog.String("code", p.Code))
+	}
+	if p.Instance != "" {
+		attrs = append(attrs, slog.String("instance", p.Instance))
+	}
+	if p.err != nil {
+		attrs = append(attrs, slog.String("cause", p.err.Error()))
+	}
+	return attrs
+}
+
+// LogValue implements slog.LogValuer, adding the number of validation errors.
+func (p *BadRequest) LogValue() slog.Value {
+	if p == nil {
+		return slog.GroupValue()
+	}
+	attrs := p.DefaultProblem.logAttrs()
+	if n := len(p.InvalidParams) + len(p.Errors); n > 0 {
+		attrs = append(attrs, slog.Int("errors", n))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer.
+func (p *CodeProblem) LogValue() slog.Value {
+	if p == nil {
+		return slog.GroupValue()
+	}
+	return p.DefaultProblem.LogValue()
+}
+
+// LogValue implements slog.LogValuer without building the problem of a wrapped error.
+func (err *ProblemError) LogValue() slog.Value {
+	if err.err != nil && err.problem == nil {
+		attrs := []slog.Attr{slog.Int("status", http.StatusInternalServerError)}
+		if err.Path != "" {
+			attrs = append(attrs, slog.String("instance", err.Path))
+		}
+		return slog.GroupValue(append(attrs, slog.String("cause", err.err.Error()))...)
+	}
+	if v, ok := err.problem.(slog.LogValuer); ok {
+		return v.LogValue()
+	}
+	return slog.StringValue(err.Error())
+}
+
+// SlogHandler expands error attributes wrapping a ProblemError into grouped problem attributes.
+type SlogHandler struct {
+	slog.Handler
+}
+
+func NewSlogHandler(h slog.Handler) *SlogHandler {
+	return &SlogHandler{Handler: h}
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(expandProblem(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		expanded = append(expanded, expandProblem(a))
+	}
+	return &SlogHandler{Handler: h.Handler.WithAttrs(expanded)}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func expandProblem(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny:
+		err, ok := a.Value.Any().(error)
+		if !ok {
+			return a
+		}
+		pe := &ProblemError{}
+		if !errors.As(err, &pe) {
+			return a
+		}
+		v := pe.LogValue()
+		if err != error(pe) && v.Kind() == slog.KindGroup {
+			v = slog.GroupValue(append([]slog.Attr{slog.String("message", err.Error())}, v.Group()...)...)
+		}
+		return slog.Attr{Key: a.Key, Value: v}
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		expanded := make([]slog.Attr, 0, len(attrs))
+		for _, v := range attrs {
+			expanded = append(expanded, expandProblem(v))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+	}
+	return a
+}
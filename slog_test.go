@@ -0,0 +1,78 @@
+package problems
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	p := New(Instance("/users"), Wrap(errors.New("boom")), Code("E001")).InternalServerError("failure")
+	logger.Info("problem", "problem", p)
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	v, _ := m["problem"].(map[string]interface{})
+	if v["status"] != float64(http.StatusInternalServerError) || v["code"] != "E001" || v["instance"] != "/users" || v["cause"] != "boom" {
+		t.Errorf("expect = %v, actual = %v", "500 E001 /users boom", v)
+	}
+	if _, ok := v["detail"]; ok {
+		t.Errorf("expect = no detail, actual = %v", v["detail"])
+	}
+
+	buf.Reset()
+	br := &BadRequest{
+		DefaultProblem: NewProblem(http.StatusBadRequest),
+		Errors:         []ValidationError{{Detail: "required", Pointer: "#/name"}},
+		InvalidParams:  []InvalidParam{{Name: "age", Reason: "min"}},
+	}
+	logger.Info("problem", "problem", br)
+	m = map[string]interface{}{}
+	_ = json.Unmarshal(buf.Bytes(), &m)
+	if v, _ := m["problem"].(map[string]interface{}); v["errors"] != float64(2) || v["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("expect = %v, actual = %v", 2, v)
+	}
+
+	var _ slog.LogValuer = &CodeProblem{}
+	if v := WrapError(errors.New("boom")).(slog.LogValuer).LogValue(); v.Kind() != slog.KindGroup || len(v.Group()) != 2 {
+		t.Errorf("expect = %v, actual = %v", slog.KindGroup, v)
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil)))
+	err := fmt.Errorf("get user: %w", New(Code("NOT_FOUND")).NotFound("user not found").Wrap())
+	logger.With("request", "1").ErrorContext(context.TODO(), "failed", "error", err)
+	m := map[string]interface{}{}
+	if e := json.Unmarshal(buf.Bytes(), &m); e != nil {
+		t.Fatal(e)
+	}
+	v, ok := m["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expect = group, actual = %v", m["error"])
+	}
+	if v["status"] != float64(http.StatusNotFound) || v["code"] != "NOT_FOUND" || v["message"] != err.Error() {
+		t.Errorf("expect = %v, actual = %v", "404 NOT_FOUND", v)
+	}
+	if m["request"] != "1" {
+		t.Errorf("expect = %v, actual = %v", "1", m["request"])
+	}
+
+	buf.Reset()
+	logger.Error("failed", "error", errors.New("plain"))
+	m = map[string]interface{}{}
+	_ = json.Unmarshal(buf.Bytes(), &m)
+	if m["error"] != "plain" {
+		t.Errorf("expect = %v, actual = %v", "plain", m["error"])
+	}
+}
@@ -0,0 +1,134 @@
+package problems
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/goccha/http-constants/pkg/headers"
+)
+
+// Translate localizes the title, the validation reasons and the redacted detail of the problem with the translator,
+// and sets the Content-Language header. Titles are translated with the English status text as the key.
+func Translate(trans ut.Translator) Option {
+	return func(p DefaultParams) Problem {
+		if dp := defaultProblemOf(p.(Problem)); dp != nil && trans != nil {
+			dp.translator = trans
+		}
+		return p.(Problem)
+	}
+}
+
+// AcceptLanguage localizes the problem with the translator best matching the Accept-Language header of the request,
+// or the fallback translator of uni if there is no match or r is nil.
+func AcceptLanguage(uni *ut.UniversalTranslator, r *http.Request) Option {
+	var accept string
+	if r != nil {
+		accept = r.Header.Get(headers.AcceptLanguage)
+	}
+	trans, _ := uni.FindTranslator(parseAcceptLanguage(accept)...)
+	return Translate(trans)
+}
+
+// RegisterTitles adds translations of the titles of the statuses.
+func RegisterTitles(trans ut.Translator, titles map[int]string) error {
+	for status, title := range titles {
+		if err := trans.Add(http.StatusText(status), title, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAcceptLanguage returns the language tags of the header by descending quality.
+func parseAcceptLanguage(accept string) []string {
+	type tag struct {
+		name    string
+		quality float64
+	}
+	var tags []tag
+	for _, v := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(v), ";")
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" {
+			continue
+		}
+		q := 1.0
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil || q <= 0 || q > 1 {
+				continue
+			}
+		}
+		tags = append(tags, tag{name: name, quality: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	names := make([]string, 0, len(tags)*2)
+	for _, t := range tags {
+		names = append(names, t.name)
+		if base, _, ok := strings.Cut(t.name, "-"); ok {
+			names = append(names, base)
+		}
+	}
+	return names
+}
+
+func recordFieldErrors(m map[int]validator.FieldError, offset int, ve validator.ValidationErrors) map[int]validator.FieldError {
+	if len(ve) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[int]validator.FieldError, len(ve))
+	}
+	for i, fe := range ve {
+		m[offset+i] = fe
+	}
+	return m
+}
+
+// localize applies the translator set by Translate.
+func localize(p Problem) {
+	dp := defaultProblemOf(p)
+	if dp == nil || dp.translator == nil {
+		return
+	}
+	trans := dp.translator
+	if title, err := trans.T(dp.Title); err == nil && title != "" {
+		dp.Title = title
+	}
+	if dp.Detail == RedactedDetail {
+		if detail, err := trans.T(RedactedDetail); err == nil && detail != "" {
+			dp.Detail = detail
+		}
+	}
+	if br := badRequestOf(p); br != nil {
+		for i, fe := range br.paramErrors {
+			if reason, ok := translateFieldError(fe, trans); ok && i < len(br.InvalidParams) {
+				br.InvalidParams[i].Reason = reason
+			}
+		}
+		for i, fe := range br.fieldErrors {
+			if detail, ok := translateFieldError(fe, trans); ok && i < len(br.Errors) {
+				br.Errors[i].Detail = detail
+			}
+		}
+	}
+	if dp.header == nil {
+		dp.header = http.Header{}
+	}
+	dp.header.Set(headers.ContentLanguage, strings.ReplaceAll(trans.Locale(), "_", "-"))
+}
+
+// translateFieldError returns false when no translation is registered for the tag.
+func translateFieldError(fe validator.FieldError, trans ut.Translator) (string, bool) {
+	s := fe.Translate(trans)
+	if s == "" || s == fe.Error() {
+		return "", false
+	}
+	return s, true
+}
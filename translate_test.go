@@ -0,0 +1,99 @@
+package problems
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	jaTranslations "github.com/go-playground/validator/v10/translations/ja"
+	"github.com/goccha/http-constants/pkg/headers"
+)
+
+type signup struct {
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age" validate:"gte=18"`
+}
+
+func newTranslators(t *testing.T, v *validator.Validate) *ut.UniversalTranslator {
+	uni := ut.New(en.New(), en.New(), ja.New())
+	enTrans, _ := uni.GetTranslator("en")
+	jaTrans, _ := uni.GetTranslator("ja")
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		t.Fatal(err)
+	}
+	if err := jaTranslations.RegisterDefaultTranslations(v, jaTrans); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTitles(jaTrans, map[int]string{http.StatusBadRequest: "不正なリクエスト"}); err != nil {
+		t.Fatal(err)
+	}
+	return uni
+}
+
+func TestTranslate(t *testing.T) {
+	v := validator.New()
+	uni := newTranslators(t, v)
+	err := v.Struct(&signup{Age: 10})
+
+	r := httptest.NewRequest(http.MethodPost, "/signup", nil)
+	r.Header.Set(headers.AcceptLanguage, "fr;q=0.9, ja-JP, en;q=0.5")
+	p := New(AcceptLanguage(uni, r), ValidationErrors(err), InvalidParams(err)).BadRequest("invalid")
+	br := badRequestOf(p)
+	if br.Title != "不正なリクエスト" {
+		t.Errorf("expect = %v, actual = %v", "不正なリクエスト", br.Title)
+	}
+	expect := []string{"Nameは必須フィールドです", "Ageは18以上でなければなりません"}
+	if actual := []string{br.Errors[0].Detail, br.Errors[1].Detail}; !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect = %v, actual = %v", expect, actual)
+	}
+	if actual := []string{br.InvalidParams[0].Reason, br.InvalidParams[1].Reason}; !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect = %v, actual = %v", expect, actual)
+	}
+
+	w := httptest.NewRecorder()
+	p.JSON(context.TODO(), w)
+	if v := w.Header().Get(headers.ContentLanguage); v != "ja" {
+		t.Errorf("expect = %v, actual = %v", "ja", v)
+	}
+}
+
+func TestTranslate_Fallback(t *testing.T) {
+	v := validator.New()
+	uni := newTranslators(t, v)
+	err := v.Struct(&signup{Name: "a", Age: 10})
+
+	r := httptest.NewRequest(http.MethodPost, "/signup", nil)
+	r.Header.Set(headers.AcceptLanguage, "de")
+	p := New(ValidationErrors(nil, ValidationError{Detail: "custom", Pointer: "#/x"}), AcceptLanguage(uni, r), ValidationErrors(err)).BadRequest("")
+	br := badRequestOf(p)
+	if br.Title != "Bad Request" || br.Errors[0].Detail != "custom" || br.Errors[1].Detail != "Age must be 18 or greater" {
+		t.Errorf("expect = %v, actual = %v", "Age must be 18 or greater", br.Errors)
+	}
+	if v := br.Header().Get(headers.ContentLanguage); v != "en" {
+		t.Errorf("expect = %v, actual = %v", "en", v)
+	}
+
+	p = New(AcceptLanguage(uni, nil), ValidationErrors(err)).BadRequest("")
+	if br = badRequestOf(p); br.Errors[0].Detail != "Age must be 18 or greater" {
+		t.Errorf("expect = %v, actual = %v", "Age must be 18 or greater", br.Errors)
+	}
+
+	p = New(ValidationErrors(validator.New().Struct(&signup{Age: 18}))).BadRequest("")
+	if br = badRequestOf(p); br.Errors[0].Detail != "required" {
+		t.Errorf("expect = %v, actual = %v", "required", br.Errors[0].Detail)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	expect := []string{"ja-JP", "ja", "en-US", "en"}
+	if actual := parseAcceptLanguage("en-US;q=0.8, *;q=0.1, ja-JP, fr;q=0"); !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect = %v, actual = %v", expect, actual)
+	}
+}